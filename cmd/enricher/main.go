@@ -4,14 +4,17 @@ import (
 	"enricher/configs"
 	"enricher/internal/enricher"
 	"enricher/internal/enricher/cache"
-	"enricher/internal/enricher/dto"
 	"enricher/internal/enricher/executors"
+	"enricher/internal/jobs"
+	"enricher/internal/jobs/store"
 	"enricher/internal/server/handlers"
 	"enricher/internal/server/middlewares"
+	"enricher/internal/uploads"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 func main() {
@@ -43,9 +46,25 @@ func run(configPath string) error {
 
 	cacheClient := getCacheClient(*appConfig.Cache)
 
-	enricherExecutorService := getExecutorService(cacheClient, enrichers)
+	enricherExecutorService := getExecutorService(*appConfig.Executor, *appConfig.Cache, cacheClient, enrichers, appConfig.Profiles)
 
-	if err := startServer(*appConfig.Server, *appConfig.API, enricherExecutorService); err != nil {
+	jobStore, err := getJobStore(*appConfig.Store)
+	if err != nil {
+		return fmt.Errorf("failed to open job store: %w", err)
+	}
+	defer jobStore.Close()
+
+	fileStore, err := getFileStore(*appConfig.Uploads)
+	if err != nil {
+		return fmt.Errorf("failed to create uploads store: %w", err)
+	}
+
+	jobManager, err := getJobManager(*appConfig.Jobs, enricherExecutorService, jobStore, fileStore)
+	if err != nil {
+		return fmt.Errorf("failed to start job manager: %w", err)
+	}
+
+	if err := startServer(*appConfig.Server, *appConfig.API, *appConfig.Executor, enricherExecutorService, jobManager, enrichers, fileStore); err != nil {
 		return fmt.Errorf("server error: %w", err)
 	}
 
@@ -67,11 +86,11 @@ func getConfigs(configPath string) (configs.Config, error) {
 	return *appConfig, nil
 }
 
-func getEnrichers(config configs.EnrichersConfig) (map[dto.EnricherArgType][]dto.Enricher, error) {
+func getEnrichers(config configs.EnrichersConfig) (enricher.EnricherRegistry, error) {
 	log.Println("Loading enrichers...")
-	enricherManager := enricher.NewEnricherManager()
+	enricherManager := enricher.NewEnricherManager(config)
 
-	enrichers, err := enricherManager.GetEnrichers(config.Path)
+	_, err := enricherManager.GetEnrichers(config.Path)
 
 	if err != nil {
 		log.Printf("Error while enrichers load: %v", err)
@@ -79,19 +98,90 @@ func getEnrichers(config configs.EnrichersConfig) (map[dto.EnricherArgType][]dto
 	}
 	log.Println("Configs loaded successfully")
 
-	return enrichers, nil
+	if config.Watch {
+		if err := enricherManager.WatchEnrichers(config.Path); err != nil {
+			log.Printf("Error while enrichers watch: %v", err)
+			return nil, err
+		}
+		log.Printf("Watching enrichers in %s", config.Path)
+	}
+
+	return enricherManager, nil
 }
 
-func startServer(config configs.ServerConfig, apiConfig configs.APIConfig, executorService executors.EnricherExecutorService) error {
+func startServer(config configs.ServerConfig, apiConfig configs.APIConfig, executorConfig configs.ExecutorConfig, executorService executors.EnricherExecutorService, jobManager *jobs.JobManager, enrichers enricher.EnricherRegistry, fileStore *uploads.FileStore) error {
 	log.Println("Configuring server...")
 
 	enrichmentHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
-		handlers.Enrichment(response, request, executorService)
+		handlers.Enrichment(response, request, jobManager, fileStore)
+	})
+
+	syncTimeout := time.Duration(config.SyncTimeout) * time.Second
+
+	enrichmentSyncHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.EnrichmentSync(response, request, executorService, fileStore, syncTimeout)
+	})
+
+	enrichmentBatchHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.EnrichmentBatch(response, request, jobManager, executorConfig.Batch.MaxSize)
 	})
 
 	authEnrichmentHandler := middlewares.AuthMiddleware(enrichmentHandler, apiConfig)
+	authEnrichmentSyncHandler := middlewares.AuthMiddleware(enrichmentSyncHandler, apiConfig)
+
+	jobHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.Job(response, request, jobManager)
+	})
+
+	jobResultsHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.JobResults(response, request, jobManager)
+	})
+
+	loadReportHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.EnrichersLoadReport(response, request, enrichers)
+	})
+
+	reloadHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.ReloadEnrichers(response, request, enrichers)
+	})
+
+	enableHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.SetEnricherEnabled(response, request, enrichers, executorService, true)
+	})
+
+	disableHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.SetEnricherEnabled(response, request, enrichers, executorService, false)
+	})
+
+	updateEnricherHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.UpdateEnricher(response, request, enrichers, executorService)
+	})
+
+	detectHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.Detect(response, request)
+	})
+
+	enrichersHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.Enrichers(response, request, executorService)
+	})
+
+	enricherHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handlers.Enricher(response, request, executorService)
+	})
 
 	http.Handle("/enrichment", authEnrichmentHandler)
+	http.Handle("/enrichment/sync", authEnrichmentSyncHandler)
+	http.Handle("POST /enrichment/batch", middlewares.AuthMiddleware(enrichmentBatchHandler, apiConfig))
+	http.Handle("GET /jobs/{id}", middlewares.AuthMiddleware(jobHandler, apiConfig))
+	http.Handle("GET /jobs/{id}/results", middlewares.AuthMiddleware(jobResultsHandler, apiConfig))
+	http.Handle("POST /detect", middlewares.AuthMiddleware(detectHandler, apiConfig))
+	http.Handle("GET /enrichers", middlewares.AuthMiddleware(enrichersHandler, apiConfig))
+	http.Handle("GET /enrichers/{name}", middlewares.AuthMiddleware(enricherHandler, apiConfig))
+	http.Handle("GET /admin/enrichers/report", middlewares.AdminMiddleware(loadReportHandler, apiConfig))
+	http.Handle("POST /admin/enrichers/reload", middlewares.AdminMiddleware(reloadHandler, apiConfig))
+	http.Handle("POST /admin/enrichers/{name}/enable", middlewares.AdminMiddleware(enableHandler, apiConfig))
+	http.Handle("POST /admin/enrichers/{name}/disable", middlewares.AdminMiddleware(disableHandler, apiConfig))
+	http.Handle("PATCH /admin/enrichers/{name}", middlewares.AdminMiddleware(updateEnricherHandler, apiConfig))
 
 	serverHost := fmt.Sprintf("%s:%d", config.Host, config.Port)
 	fmt.Printf("Starting server %s...\n", serverHost)
@@ -113,11 +203,54 @@ func getCacheClient(config configs.CacheConfig) cache.CacheClient {
 	)
 }
 
-func getExecutorService(cacheClient cache.CacheClient, enrichers map[dto.EnricherArgType][]dto.Enricher) executors.EnricherExecutorService {
+func getExecutorService(config configs.ExecutorConfig, cacheConfig configs.CacheConfig, cacheClient cache.CacheClient, enrichers enricher.EnricherRegistry, profiles map[string]configs.ProfileConfig) executors.EnricherExecutorService {
 	log.Println("Creating enrichers executor service...")
 
 	return executors.EnricherExecutorService(*executors.NewEnricherCmdExecutorService(
 		&cacheClient,
 		enrichers,
+		config,
+		cacheConfig,
+		profiles,
 	))
 }
+
+func getJobStore(config configs.StoreConfig) (store.JobStore, error) {
+	log.Println("Creating job store...")
+
+	switch config.Type {
+	case "memory":
+		return store.NewInMemoryJobStore(), nil
+	case "bolt":
+		return store.NewBoltJobStore(config.Path)
+	default:
+		return nil, fmt.Errorf("unknown job store type: %s", config.Type)
+	}
+}
+
+func getFileStore(config configs.UploadsConfig) (*uploads.FileStore, error) {
+	log.Println("Creating uploads store...")
+
+	return uploads.NewFileStore(config)
+}
+
+func getJobManager(config configs.JobsConfig, executorService executors.EnricherExecutorService, jobStore store.JobStore, fileStore *uploads.FileStore) (*jobs.JobManager, error) {
+	log.Println("Creating job manager...")
+
+	jobManager := jobs.NewJobManager(
+		executorService,
+		jobStore,
+		fileStore,
+		config,
+	)
+
+	if err := jobManager.RemoveUnusedUploads(); err != nil {
+		log.Printf("Error removing unused uploads: %v", err)
+	}
+	if err := jobManager.ResumeUnfinishedJobs(); err != nil {
+		return nil, err
+	}
+	jobManager.StartCleanup()
+
+	return jobManager, nil
+}
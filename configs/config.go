@@ -11,31 +11,88 @@ type Config struct {
 	Enrichers *EnrichersConfig
 	Server    *ServerConfig
 	Cache     *CacheConfig
+	Store     *StoreConfig
 	API       *APIConfig
+	Jobs      *JobsConfig
+	Executor  *ExecutorConfig
+	Uploads   *UploadsConfig
+	Profiles  map[string]ProfileConfig
 }
 
 type ServerConfig struct {
-	Host string
-	Port int
+	Host        string
+	Port        int
+	SyncTimeout int
 }
 
 type EnrichersConfig struct {
-	Path string
+	Path          string
+	Watch         bool
+	Strict        bool
+	OverridesPath string
+	Args          map[string]map[string]any
 }
 
 type CacheConfig struct {
-	Address  string
-	Password string
-	Db       int
+	Address    string
+	Password   string
+	Db         int
+	DefaultTTL int
+}
+
+type StoreConfig struct {
+	Type string
+	Path string
 }
 
 type APIKey struct {
-	Name string
-	Key  string
+	Name    string
+	Key     string
+	Profile string
 }
 
 type APIConfig struct {
-	Keys []APIKey
+	Keys      []APIKey
+	AdminKeys []APIKey
+}
+
+type ExecutorConfig struct {
+	DefaultTimeout int
+	MaxTimeout     int
+	MaxConcurrency int
+	SchemaPolicy   string
+	Pivot          PivotConfig
+	Batch          BatchConfig
+}
+
+type PivotConfig struct {
+	MaxDepth       int
+	MaxFanOut      int
+	MaxObservables int
+}
+
+type BatchConfig struct {
+	MaxSize     int
+	Concurrency int
+}
+
+type ProfileConfig struct {
+	Enrichers []string
+	Exclude   []string
+	Tags      []string
+	Args      map[string]map[string]any
+	Timeouts  map[string]int64
+}
+
+type UploadsConfig struct {
+	Dir     string
+	MaxSize int64
+}
+
+type JobsConfig struct {
+	Retention          int
+	WebhookConcurrency int
+	WebhookTimeout     int
 }
 
 func loadConfig(filePath string) (*Config, error) {
@@ -45,6 +102,26 @@ func loadConfig(filePath string) (*Config, error) {
 	v := viper.New()
 	v.SetConfigFile(filePath)
 	v.SetConfigType("yaml")
+	v.SetDefault("server.syncTimeout", 60)
+	v.SetDefault("enrichers.watch", false)
+	v.SetDefault("enrichers.overridesPath", "enrichers_overrides.json")
+	v.SetDefault("jobs.retention", 3600)
+	v.SetDefault("jobs.webhookConcurrency", 4)
+	v.SetDefault("jobs.webhookTimeout", 10)
+	v.SetDefault("cache.defaultTTL", 300)
+	v.SetDefault("executor.defaultTimeout", 30)
+	v.SetDefault("executor.maxTimeout", 300)
+	v.SetDefault("executor.maxConcurrency", 8)
+	v.SetDefault("executor.schemaPolicy", "warn")
+	v.SetDefault("executor.pivot.maxDepth", 0)
+	v.SetDefault("executor.pivot.maxFanOut", 10)
+	v.SetDefault("executor.pivot.maxObservables", 50)
+	v.SetDefault("executor.batch.maxSize", 10000)
+	v.SetDefault("executor.batch.concurrency", 4)
+	v.SetDefault("store.type", "memory")
+	v.SetDefault("store.path", "jobs.db")
+	v.SetDefault("uploads.dir", "")
+	v.SetDefault("uploads.maxSize", 32<<20)
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
@@ -54,9 +131,43 @@ func loadConfig(filePath string) (*Config, error) {
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 	return &config, nil
 }
 
+// validateConfig rejects the limits which would stop every execution, a
+// zero timeout would kill the plugins as soon as they start.
+func validateConfig(config *Config) error {
+	if config.Server != nil && config.Server.SyncTimeout <= 0 {
+		return fmt.Errorf("server.syncTimeout must be positive: %d", config.Server.SyncTimeout)
+	}
+	if config.Executor != nil {
+		if config.Executor.DefaultTimeout <= 0 {
+			return fmt.Errorf("executor.defaultTimeout must be positive: %d", config.Executor.DefaultTimeout)
+		}
+		if config.Executor.MaxTimeout < 0 {
+			return fmt.Errorf("executor.maxTimeout must not be negative: %d", config.Executor.MaxTimeout)
+		}
+		if config.Executor.MaxConcurrency <= 0 {
+			return fmt.Errorf("executor.maxConcurrency must be positive: %d", config.Executor.MaxConcurrency)
+		}
+		switch config.Executor.SchemaPolicy {
+		case "reject", "warn", "pass":
+		default:
+			return fmt.Errorf("unknown executor.schemaPolicy: %s", config.Executor.SchemaPolicy)
+		}
+		if config.Executor.Batch.Concurrency <= 0 {
+			return fmt.Errorf("executor.batch.concurrency must be positive: %d", config.Executor.Batch.Concurrency)
+		}
+	}
+	if config.Jobs != nil && config.Jobs.WebhookTimeout <= 0 {
+		return fmt.Errorf("jobs.webhookTimeout must be positive: %d", config.Jobs.WebhookTimeout)
+	}
+	return nil
+}
+
 type ConfigManager struct {
 	config *Config
 	mu     sync.RWMutex
@@ -0,0 +1,76 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			config: "server:\n  port: 8080\nexecutor:\n  maxTimeout: 0\njobs:\n  retention: 60\n",
+		},
+		{
+			name:    "zero sync timeout",
+			config:  "server:\n  syncTimeout: 0\n",
+			wantErr: "server.syncTimeout",
+		},
+		{
+			name:    "zero default timeout",
+			config:  "executor:\n  defaultTimeout: 0\n",
+			wantErr: "executor.defaultTimeout",
+		},
+		{
+			name:    "negative max timeout",
+			config:  "executor:\n  maxTimeout: -1\n",
+			wantErr: "executor.maxTimeout",
+		},
+		{
+			name:    "zero max concurrency",
+			config:  "executor:\n  maxConcurrency: 0\n",
+			wantErr: "executor.maxConcurrency",
+		},
+		{
+			name:    "unknown schema policy",
+			config:  "executor:\n  schemaPolicy: drop\n",
+			wantErr: "executor.schemaPolicy",
+		},
+		{
+			name:    "zero batch concurrency",
+			config:  "executor:\n  batch:\n    concurrency: 0\n",
+			wantErr: "executor.batch.concurrency",
+		},
+		{
+			name:    "zero webhook timeout",
+			config:  "jobs:\n  webhookTimeout: 0\n",
+			wantErr: "jobs.webhookTimeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := loadConfig(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadConfig() error = %v, want %s error", err, tt.wantErr)
+			}
+		})
+	}
+}
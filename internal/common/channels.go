@@ -1,52 +1,69 @@
 package common
 
 import (
+	"context"
 	"sync"
 )
 
-type Processor[E any, I any, R any] func(E, I) (R, error)
+type Processor[E any, I any, R any] func(context.Context, E, I) (R, error)
 
+type Outcome[R any] struct {
+	Result R
+	Err    error
+}
+
+// ParallelExecute runs the processor for every entity with at most
+// maxConcurrency calls in flight (unlimited when it is not positive).
+// Outcomes keep the order of entities. Entities not started before the
+// context is done get the context error as their outcome.
 func ParallelExecute[E any, I any, R any](
+	ctx context.Context,
 	inputData I,
 	entities []E,
 	processor Processor[E, I, R],
-) ([]R, error) {
-	results := []R{}
-	errors := []error{}
+	maxConcurrency int,
+) []Outcome[R] {
+	outcomes := make([]Outcome[R], len(entities))
+
+	if maxConcurrency <= 0 || maxConcurrency > len(entities) {
+		maxConcurrency = len(entities)
+	}
+	semaphore := make(chan struct{}, maxConcurrency)
 
 	var wg sync.WaitGroup
-	resultChan := make(chan R, len(entities))
-	errorChan := make(chan error)
+	for i, entity := range entities {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			outcomes[i].Err = ctx.Err()
+			continue
+		}
 
-	for _, entity := range entities {
 		wg.Add(1)
-
-		go func(e interface{}) {
+		go func(i int, entity E) {
 			defer wg.Done()
+			defer func() { <-semaphore }()
 
-			result, err := processor(entity, inputData)
-
-			if err != nil {
-				errorChan <- err
-			} else {
-				resultChan <- result
+			if err := ctx.Err(); err != nil {
+				outcomes[i].Err = err
+				return
 			}
-		}(entity)
-	}
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-		close(errorChan)
-	}()
 
-	for result := range resultChan {
-		results = append(results, result)
-	}
-	for err := range errorChan {
-		errors = append(errors, err)
+			result, err := processor(ctx, entity, inputData)
+			outcomes[i] = Outcome[R]{Result: result, Err: err}
+		}(i, entity)
 	}
+	wg.Wait()
 
-	return results, MergeErrors(errors)
+	return outcomes
+}
 
+func OutcomesErrors[R any](outcomes []Outcome[R]) error {
+	var errs []error
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			errs = append(errs, outcome.Err)
+		}
+	}
+	return MergeErrors(errs)
 }
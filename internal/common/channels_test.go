@@ -0,0 +1,145 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelExecute(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	tests := []struct {
+		name           string
+		entities       []int
+		maxConcurrency int
+		wantInFlight   int32
+	}{
+		{"no entities", nil, 2, 0},
+		{"limited", []int{1, 2, 3, 4, 5, 6}, 2, 2},
+		{"single worker", []int{1, 2, 3}, 1, 1},
+		{"unlimited", []int{1, 2, 3, 4}, 0, 4},
+		{"limit above entities", []int{1, 2, 3}, 10, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, maxInFlight atomic.Int32
+
+			processor := func(ctx context.Context, entity int, prefix string) (string, error) {
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					seen := maxInFlight.Load()
+					if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+
+				// later entities finish first, outcomes still keep the order
+				time.Sleep(time.Duration(len(tt.entities)-entity+1) * 10 * time.Millisecond)
+				if entity%2 == 1 {
+					return "", errOdd
+				}
+				return fmt.Sprintf("%s%d", prefix, entity), nil
+			}
+
+			outcomes := ParallelExecute(context.Background(), "entity-", tt.entities, processor, tt.maxConcurrency)
+
+			if len(outcomes) != len(tt.entities) {
+				t.Fatalf("ParallelExecute() returned %d outcomes, want %d", len(outcomes), len(tt.entities))
+			}
+			for i, entity := range tt.entities {
+				want := Outcome[string]{Result: fmt.Sprintf("entity-%d", entity)}
+				if entity%2 == 1 {
+					want = Outcome[string]{Err: errOdd}
+				}
+				if outcomes[i] != want {
+					t.Errorf("outcome %d = %+v, want %+v", i, outcomes[i], want)
+				}
+			}
+			if got := maxInFlight.Load(); got != tt.wantInFlight {
+				t.Errorf("max in flight = %d, want %d", got, tt.wantInFlight)
+			}
+		})
+	}
+}
+
+func TestParallelExecuteCancellation(t *testing.T) {
+	tests := []struct {
+		name         string
+		cancelAfter  int
+		entities     int
+		wantExecuted int32
+	}{
+		{"cancelled before start", 0, 3, 0},
+		{"cancelled while running", 2, 5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var executed atomic.Int32
+			processor := func(ctx context.Context, entity int, _ struct{}) (int, error) {
+				if executed.Add(1) == int32(tt.cancelAfter) {
+					cancel()
+				}
+				return entity, nil
+			}
+			if tt.cancelAfter == 0 {
+				cancel()
+			}
+
+			entities := make([]int, tt.entities)
+			for i := range entities {
+				entities[i] = i
+			}
+			outcomes := ParallelExecute(ctx, struct{}{}, entities, processor, 1)
+
+			if got := executed.Load(); got != tt.wantExecuted {
+				t.Errorf("executed %d entities, want %d", got, tt.wantExecuted)
+			}
+			for i, outcome := range outcomes {
+				if i < int(tt.wantExecuted) {
+					if outcome.Err != nil || outcome.Result != i {
+						t.Errorf("outcome %d = %+v, want result %d", i, outcome, i)
+					}
+					continue
+				}
+				if !errors.Is(outcome.Err, context.Canceled) {
+					t.Errorf("outcome %d error = %v, want %v", i, outcome.Err, context.Canceled)
+				}
+			}
+		})
+	}
+}
+
+func TestOutcomesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []Outcome[int]
+		want     string
+	}{
+		{"no outcomes", nil, ""},
+		{"no errors", []Outcome[int]{{Result: 1}, {Result: 2}}, ""},
+		{"some errors", []Outcome[int]{{Err: errors.New("first")}, {Result: 2}, {Err: errors.New("second")}}, "first\nsecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := OutcomesErrors(tt.outcomes)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("OutcomesErrors() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
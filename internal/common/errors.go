@@ -8,11 +8,22 @@ func MergeErrors(errs []error) error {
 	if len(errs) == 0 {
 		return nil
 	}
-	var combinedErr string
-	for _, err := range errs {
-		combinedErr += err.Error() + "\n"
+	return errors.Join(errs...)
+}
+
+func SplitErrors(err error) []string {
+	if err == nil {
+		return nil
 	}
-	return errors.New(combinedErr)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	var messages []string
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, SplitErrors(e)...)
+	}
+	return messages
 }
 
 func MergeErrorsMessages(errs []string) error {
@@ -0,0 +1,192 @@
+package args
+
+import (
+	"enricher/internal/enricher/dto"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var envNameReplacer = regexp.MustCompile(`[^A-Z0-9]+`)
+
+func Convert(value any, argType dto.EnricherConfigArgType) (any, error) {
+	switch argType {
+	case dto.StringArg:
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+	case dto.IntArg:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return int(v), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case string:
+			if parsed, err := strconv.Atoi(v); err == nil {
+				return parsed, nil
+			}
+		}
+	case dto.BoolArg:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if parsed, err := strconv.ParseBool(v); err == nil {
+				return parsed, nil
+			}
+		}
+	case dto.FloatArg:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				return parsed, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown argument type: %s", argType)
+	}
+	return nil, fmt.Errorf("invalid type for argument: %v, expected %s", value, argType)
+}
+
+// Resolve computes the load-time values of the enricher arguments. Values are
+// taken from the application config, then from the ENRICHER_<ENRICHER>_<ARG>
+// environment variable, then from the declared default.
+func Resolve(enricher dto.Enricher, configured map[string]any) (map[string]any, error) {
+	values := make(map[string]any)
+
+	for _, arg := range enricher.ConfigArgs {
+		value, found := lookup(configured, arg.Name)
+		if !found {
+			value, found = os.LookupEnv(SourceEnvName(enricher.Name, arg.Name))
+		}
+		if !found && arg.DefaultValue != nil {
+			value, found = arg.DefaultValue, true
+		}
+
+		if !found {
+			if arg.Required {
+				return nil, fmt.Errorf("required argument not provided: %s", arg.Name)
+			}
+			continue
+		}
+
+		converted, err := Convert(value, arg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", arg.Name, err)
+		}
+		values[arg.Name] = converted
+	}
+
+	return values, nil
+}
+
+func Configured(config map[string]map[string]any, enricherName string) map[string]any {
+	for name, values := range config {
+		if strings.EqualFold(name, enricherName) {
+			return values
+		}
+	}
+	return nil
+}
+
+func Merge(enricher dto.Enricher, overrides map[string]any) (map[string]any, error) {
+	values := make(map[string]any, len(enricher.Args))
+	for name, value := range enricher.Args {
+		values[name] = value
+	}
+
+	for name, value := range overrides {
+		arg, found := findArg(enricher.ConfigArgs, name)
+		if !found {
+			return nil, fmt.Errorf("unknown argument for enricher %s: %s", enricher.Name, name)
+		}
+
+		converted, err := Convert(value, arg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", arg.Name, err)
+		}
+		values[arg.Name] = converted
+	}
+
+	return values, nil
+}
+
+// Name returns the declared spelling of the argument, which is matched
+// case-insensitively. Undeclared names are returned unchanged.
+func Name(enricher dto.Enricher, name string) string {
+	if arg, found := findArg(enricher.ConfigArgs, name); found {
+		return arg.Name
+	}
+	return name
+}
+
+// IsSecret reports whether the argument is declared secret.
+func IsSecret(enricher dto.Enricher, name string) bool {
+	arg, found := findArg(enricher.ConfigArgs, name)
+	return found && arg.Secret
+}
+
+// Environ renders argument values as ENRICHER_ARG_<ARG>=<value> entries
+// which are passed to the enricher executable.
+func Environ(values map[string]any) []string {
+	environ := make([]string, 0, len(values))
+	for name, value := range values {
+		environ = append(environ, fmt.Sprintf("%s=%s", ExecutableEnvName(name), format(value)))
+	}
+	sort.Strings(environ)
+
+	return environ
+}
+
+func SourceEnvName(enricherName string, argName string) string {
+	return "ENRICHER_" + envName(enricherName) + "_" + envName(argName)
+}
+
+func ExecutableEnvName(argName string) string {
+	return "ENRICHER_ARG_" + envName(argName)
+}
+
+func envName(name string) string {
+	return strings.Trim(envNameReplacer.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+func format(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func findArg(configArgs []dto.EnricherConfigArg, name string) (dto.EnricherConfigArg, bool) {
+	for _, arg := range configArgs {
+		if strings.EqualFold(arg.Name, name) {
+			return arg, true
+		}
+	}
+	return dto.EnricherConfigArg{}, false
+}
+
+func lookup(values map[string]any, name string) (any, bool) {
+	for key, value := range values {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,159 @@
+package args
+
+import (
+	"enricher/internal/enricher/dto"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+var testEnricher = dto.Enricher{
+	Name: "whois-lookup",
+	ConfigArgs: []dto.EnricherConfigArg{
+		{Name: "apiKey", Type: dto.StringArg, Required: true, Secret: true},
+		{Name: "maxResults", Type: dto.IntArg, DefaultValue: float64(10)},
+		{Name: "verbose", Type: dto.BoolArg},
+		{Name: "threshold", Type: dto.FloatArg},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured map[string]any
+		env        map[string]string
+		want       map[string]any
+		wantErr    bool
+	}{
+		{
+			name:       "config values",
+			configured: map[string]any{"apikey": "config", "maxResults": "20", "verbose": true},
+			want:       map[string]any{"apiKey": "config", "maxResults": 20, "verbose": true},
+		},
+		{
+			name: "environment values",
+			env:  map[string]string{"ENRICHER_WHOIS_LOOKUP_APIKEY": "env", "ENRICHER_WHOIS_LOOKUP_THRESHOLD": "0.5"},
+			want: map[string]any{"apiKey": "env", "maxResults": 10, "threshold": 0.5},
+		},
+		{
+			name:       "config before environment",
+			configured: map[string]any{"apiKey": "config"},
+			env:        map[string]string{"ENRICHER_WHOIS_LOOKUP_APIKEY": "env"},
+			want:       map[string]any{"apiKey": "config", "maxResults": 10},
+		},
+		{
+			name:    "required arg missing",
+			wantErr: true,
+		},
+		{
+			name:       "invalid value",
+			configured: map[string]any{"apiKey": "config", "maxResults": "many"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			got, err := Resolve(testEnricher, tt.configured)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	enricher := testEnricher
+	enricher.Args = map[string]any{"apiKey": "config", "maxResults": 10}
+
+	tests := []struct {
+		name      string
+		overrides map[string]any
+		want      map[string]any
+		wantErr   bool
+	}{
+		{
+			name: "no overrides",
+			want: map[string]any{"apiKey": "config", "maxResults": 10},
+		},
+		{
+			name:      "overrides are converted",
+			overrides: map[string]any{"maxResults": float64(5), "verbose": "true"},
+			want:      map[string]any{"apiKey": "config", "maxResults": 5, "verbose": true},
+		},
+		{
+			name:      "names are matched case-insensitively",
+			overrides: map[string]any{"APIKEY": "request"},
+			want:      map[string]any{"apiKey": "request", "maxResults": 10},
+		},
+		{
+			name:      "unknown arg",
+			overrides: map[string]any{"region": "eu"},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid value",
+			overrides: map[string]any{"threshold": "high"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge(enricher, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %v, want %v", got, tt.want)
+			}
+			if enricher.Args["apiKey"] != "config" {
+				t.Errorf("Merge() modified the enricher args: %v", enricher.Args)
+			}
+		})
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	got := Environ(map[string]any{"apiKey": "key", "max-results": 5, "threshold": 0.25, "verbose": true})
+	want := []string{
+		"ENRICHER_ARG_APIKEY=key",
+		"ENRICHER_ARG_MAX_RESULTS=5",
+		"ENRICHER_ARG_THRESHOLD=0.25",
+		"ENRICHER_ARG_VERBOSE=true",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Environ() = %v, want %v", got, want)
+	}
+}
+
+func TestNameAndIsSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantName   string
+		wantSecret bool
+	}{
+		{"apiKey", "apiKey", true},
+		{"APIKEY", "apiKey", true},
+		{"maxresults", "maxResults", false},
+		{"region", "region", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Name(testEnricher, tt.name); got != tt.wantName {
+				t.Errorf("Name() = %s, want %s", got, tt.wantName)
+			}
+			if got := IsSecret(testEnricher, tt.name); got != tt.wantSecret {
+				t.Errorf("IsSecret() = %v, want %v", got, tt.wantSecret)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package enricher
+
+import (
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// validateDependencies returns the directories whose enrichers depend on a
+// missing enricher, on one which does not support all their allowed types,
+// or are part of a dependency cycle. Enrichers depending on invalid ones
+// are invalid as well.
+func validateDependencies(directories map[string]dto.Enricher) map[string]error {
+	invalid := make(map[string]error)
+
+	names := make([]string, 0, len(directories))
+	for directory := range directories {
+		names = append(names, directory)
+	}
+	sort.Strings(names)
+
+	for changed := true; changed; {
+		changed = false
+
+		enrichers := make(map[string]dto.Enricher)
+		for _, directory := range names {
+			if _, exists := invalid[directory]; !exists {
+				enrichers[directories[directory].Name] = directories[directory]
+			}
+		}
+
+		for _, directory := range names {
+			if _, exists := invalid[directory]; exists {
+				continue
+			}
+			if err := validateEnricherDependencies(directories[directory], enrichers); err != nil {
+				invalid[directory] = err
+				changed = true
+			}
+		}
+	}
+
+	resolved := make(map[string]bool)
+	for progress := true; progress; {
+		progress = false
+		for _, directory := range names {
+			enricher := directories[directory]
+			if _, exists := invalid[directory]; exists || resolved[enricher.Name] {
+				continue
+			}
+			if dependenciesResolved(enricher, resolved) {
+				resolved[enricher.Name] = true
+				progress = true
+			}
+		}
+	}
+
+	var cycle []string
+	for _, directory := range names {
+		enricher := directories[directory]
+		if _, exists := invalid[directory]; !exists && !resolved[enricher.Name] {
+			cycle = append(cycle, enricher.Name)
+		}
+	}
+	for _, directory := range names {
+		if slices.Contains(cycle, directories[directory].Name) {
+			invalid[directory] = fmt.Errorf("dependency cycle between enrichers: %s", strings.Join(cycle, ", "))
+		}
+	}
+
+	return invalid
+}
+
+func validateEnricherDependencies(enricher dto.Enricher, enrichers map[string]dto.Enricher) error {
+	for _, dependencyName := range enricher.DependsOn {
+		dependency, exists := enrichers[dependencyName]
+		if !exists {
+			return fmt.Errorf("dependency %s of enricher %s not found", dependencyName, enricher.Name)
+		}
+		for _, allowedType := range enricher.AllowedTypes {
+			if !observables.Matches(dependency.AllowedTypes, allowedType) {
+				return fmt.Errorf("dependency %s of enricher %s does not support type %s", dependencyName, enricher.Name, allowedType)
+			}
+		}
+	}
+	return nil
+}
+
+func dependenciesResolved(enricher dto.Enricher, resolved map[string]bool) bool {
+	for _, dependencyName := range enricher.DependsOn {
+		if !resolved[dependencyName] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package enricher
+
+import (
+	"enricher/internal/enricher/dto"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		directories map[string]dto.Enricher
+		wantInvalid []string
+	}{
+		{
+			name: "no dependencies",
+			directories: map[string]dto.Enricher{
+				"whois": {Name: "whois", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}},
+			},
+		},
+		{
+			name: "chain",
+			directories: map[string]dto.Enricher{
+				"dns":     {Name: "dns", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}},
+				"geo":     {Name: "geo", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"dns"}},
+				"verdict": {Name: "verdict", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"dns", "geo"}},
+			},
+		},
+		{
+			name: "dependency found by enricher name",
+			directories: map[string]dto.Enricher{
+				"dns-plugin": {Name: "dns", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}},
+				"geo":        {Name: "geo", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"dns"}},
+			},
+		},
+		{
+			name: "missing dependency",
+			directories: map[string]dto.Enricher{
+				"geo": {Name: "geo", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"dns"}},
+			},
+			wantInvalid: []string{"geo"},
+		},
+		{
+			name: "dependency does not support the type",
+			directories: map[string]dto.Enricher{
+				"dns": {Name: "dns", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}},
+				"geo": {Name: "geo", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN, dto.IP}, DependsOn: []string{"dns"}},
+			},
+			wantInvalid: []string{"geo"},
+		},
+		{
+			name: "generic dependency supports the subtype",
+			directories: map[string]dto.Enricher{
+				"reputation": {Name: "reputation", AllowedTypes: []dto.EnricherArgType{dto.HASH}},
+				"sandbox":    {Name: "sandbox", AllowedTypes: []dto.EnricherArgType{dto.SHA256}, DependsOn: []string{"reputation"}},
+			},
+		},
+		{
+			name: "specific dependency does not support the generic type",
+			directories: map[string]dto.Enricher{
+				"reputation": {Name: "reputation", AllowedTypes: []dto.EnricherArgType{dto.SHA256}},
+				"sandbox":    {Name: "sandbox", AllowedTypes: []dto.EnricherArgType{dto.HASH}, DependsOn: []string{"reputation"}},
+			},
+			wantInvalid: []string{"sandbox"},
+		},
+		{
+			name: "dependent of an invalid enricher",
+			directories: map[string]dto.Enricher{
+				"geo":     {Name: "geo", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"dns"}},
+				"verdict": {Name: "verdict", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}, DependsOn: []string{"geo"}},
+				"whois":   {Name: "whois", AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}},
+			},
+			wantInvalid: []string{"geo", "verdict"},
+		},
+		{
+			name: "self dependency",
+			directories: map[string]dto.Enricher{
+				"loop": {Name: "loop", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"loop"}},
+			},
+			wantInvalid: []string{"loop"},
+		},
+		{
+			name: "cycle",
+			directories: map[string]dto.Enricher{
+				"a":     {Name: "a", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"c"}},
+				"b":     {Name: "b", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"a"}},
+				"c":     {Name: "c", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"b"}},
+				"d":     {Name: "d", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"e"}},
+				"e":     {Name: "e", AllowedTypes: []dto.EnricherArgType{dto.URL}},
+				"after": {Name: "after", AllowedTypes: []dto.EnricherArgType{dto.URL}, DependsOn: []string{"a"}},
+			},
+			wantInvalid: []string{"a", "after", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := validateDependencies(tt.directories)
+
+			var got []string
+			for directory, err := range invalid {
+				if err == nil {
+					t.Errorf("directory %s is invalid without an error", directory)
+				}
+				got = append(got, directory)
+			}
+			sort.Strings(got)
+
+			if !slices.Equal(got, tt.wantInvalid) {
+				t.Errorf("validateDependencies() invalid = %v, want %v", got, tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package dto
+
+// BatchObservable is an observable of a batch request, its type is
+// detected when empty or "auto".
+type BatchObservable struct {
+	Data     string          `json:"data"`
+	DataType EnricherArgType `json:"type"`
+}
+
+// BatchInputData applies the request settings to every observable.
+type BatchInputData struct {
+	EnricherInputData
+	Observables []BatchObservable `json:"observables"`
+}
+
+// BatchItem is a unique observable of a batch, Duplicates counts the
+// identical inputs merged into it.
+type BatchItem struct {
+	Observable string
+	DataType   EnricherArgType
+	Status     JobStatus
+	Duplicates int    `json:",omitempty"`
+	Error      string `json:",omitempty"`
+}
+
+// BatchRejection is an input of a batch which is not enriched.
+type BatchRejection struct {
+	Observable string
+	DataType   EnricherArgType
+	Error      string
+}
+
+type BatchSummary struct {
+	JobID              string
+	Status             JobStatus
+	Total              int
+	Unique             int
+	Duplicates         int
+	Succeeded          int
+	PartiallySucceeded int
+	Failed             int
+	Items              []BatchItem
+	Rejected           []BatchRejection `json:",omitempty"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const MaskedValue = "******"
+
+type EnricherStats struct {
+	Runs        int64
+	Failures    int64
+	FailureRate float64
+	LastRunAt   *time.Time `json:",omitempty"`
+	LastStatus  JobStatus  `json:",omitempty"`
+}
+
+type EnricherInfo struct {
+	Name           string
+	Version        string `json:",omitempty"`
+	Description    string `json:",omitempty"`
+	Author         string `json:",omitempty"`
+	Source         string `json:",omitempty"`
+	AllowedTypes   []EnricherArgType
+	Tags           []string `json:",omitempty"`
+	DependsOn      []string `json:",omitempty"`
+	ConfigArgs     []EnricherConfigArg
+	Enabled        bool
+	Timeout        int64
+	CacheTTL       int64
+	CacheTTLByType map[EnricherArgType]int64 `json:",omitempty"`
+	ResultSchema   json.RawMessage           `json:",omitempty"`
+	SchemaPolicy   SchemaPolicy
+	Stats          EnricherStats
+}
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"encoding/json"
+	"enricher/internal/enricher/schema"
+)
+
 type EnricherConfigArgType string
 
 const (
@@ -9,21 +14,43 @@ const (
 	FloatArg  EnricherConfigArgType = "float"
 )
 
+type SchemaPolicy string
+
+const (
+	RejectSchemaPolicy SchemaPolicy = "reject"
+	WarnSchemaPolicy   SchemaPolicy = "warn"
+	PassSchemaPolicy   SchemaPolicy = "pass"
+)
+
 type EnricherConfigArg struct {
 	Name         string
 	Type         EnricherConfigArgType
 	Required     bool
-	DefaultValue any `json:"defaultValue,omitempty"`
+	Secret       bool `json:"secret,omitempty"`
+	DefaultValue any  `json:"defaultValue,omitempty"`
 }
 
 type Enricher struct {
-	Enabled        bool
-	Name           string
-	ExecutablePath string
-	Timeout        int64
-	AllowedTypes   []EnricherArgType
-	Author         string `json:"author,omitempty"`
-	Source         string `json:"source,omitempty"`
-	Description    string `json:"description,omitempty"`
-	ConfigArgs     []EnricherConfigArg
+	Enabled          bool
+	Name             string
+	ExecutablePath   string
+	Protocol         EnricherProtocol `json:"protocol,omitempty"`
+	Timeout          int64
+	CacheTTL         int64                     `json:"cacheTTL,omitempty"`
+	CacheTTLByType   map[EnricherArgType]int64 `json:"cacheTTLByType,omitempty"`
+	DisableCache     bool                      `json:"disableCache,omitempty"`
+	AllowedTypes     []EnricherArgType
+	Types            []ObservableTypeDefinition `json:"types,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`
+	DependsOn        []string                   `json:"dependsOn,omitempty"`
+	Version          string                     `json:"version,omitempty"`
+	Author           string                     `json:"author,omitempty"`
+	Source           string                     `json:"source,omitempty"`
+	Description      string                     `json:"description,omitempty"`
+	ConfigArgs       []EnricherConfigArg
+	ResultSchema     json.RawMessage `json:"resultSchema,omitempty"`
+	ResultSchemaPath string          `json:"resultSchemaPath,omitempty"`
+	SchemaPolicy     SchemaPolicy    `json:"schemaPolicy,omitempty"`
+	Args             map[string]any  `json:"-"`
+	CompiledSchema   *schema.Schema  `json:"-"`
 }
@@ -0,0 +1,20 @@
+package dto
+
+type DetectionRequest struct {
+	Data string
+}
+
+type DetectionCandidate struct {
+	Type       EnricherArgType
+	Value      string
+	Confidence float64
+}
+
+type DetectionResult struct {
+	Data       string
+	Type       EnricherArgType `json:",omitempty"`
+	Value      string          `json:",omitempty"`
+	Confidence float64         `json:",omitempty"`
+	Ambiguous  bool
+	Candidates []DetectionCandidate
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type EnricherArgType string
 
 const (
@@ -7,15 +9,61 @@ const (
 	HASH     EnricherArgType = "HASH"
 	FILE     EnricherArgType = "FILE"
 	USERNAME EnricherArgType = "USERNAME"
+	IP       EnricherArgType = "IP"
+	IPV4     EnricherArgType = "IPV4"
+	IPV6     EnricherArgType = "IPV6"
+	CIDR     EnricherArgType = "CIDR"
+	DOMAIN   EnricherArgType = "DOMAIN"
+	EMAIL    EnricherArgType = "EMAIL"
+	CVE      EnricherArgType = "CVE"
+	MD5      EnricherArgType = "MD5"
+	SHA1     EnricherArgType = "SHA1"
+	SHA256   EnricherArgType = "SHA256"
+	SSDEEP   EnricherArgType = "SSDEEP"
 )
 
+const AutoDetectType EnricherArgType = "auto"
+
 type EnricherInputData struct {
 	WebhookUri string `json:"uri"`
 	Data       string
-	DataType   EnricherArgType `json:"type"`
+	DataType   EnricherArgType           `json:"type"`
+	Args       map[string]map[string]any `json:"args,omitempty"`
+	Enrichers  []string                  `json:"enrichers,omitempty"`
+	Exclude    []string                  `json:"exclude,omitempty"`
+	Tags       []string                  `json:"tags,omitempty"`
+	Profile    string                    `json:"profile,omitempty"`
+	File       *FileAttributes           `json:"file,omitempty"`
+	JobID      string                    `json:"-"`
+	Upstream   map[string]EnricherResult `json:"-"`
 }
 
 type EnricherResult struct {
-	Report map[string]interface{}
-	Errors []string
+	Report      map[string]interface{}
+	Errors      []string
+	Observables []Observable `json:",omitempty"`
+}
+
+type EnricherResultEnvelope struct {
+	Enricher   string
+	Version    string `json:",omitempty"`
+	Source     string `json:",omitempty"`
+	Observable string
+	DataType   EnricherArgType
+	File       *FileAttributes `json:",omitempty"`
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   int64
+	ExitCode   int
+	Cached     bool
+	Status     JobStatus
+	Error      string `json:",omitempty"`
+	Result     EnricherResult
+}
+
+type EnrichmentResponse struct {
+	Status  JobStatus
+	Results []EnricherResultEnvelope
+	Graph   *EnrichmentGraph `json:",omitempty"`
+	Errors  []string
 }
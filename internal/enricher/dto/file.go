@@ -0,0 +1,10 @@
+package dto
+
+type FileAttributes struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	MD5      string `json:"md5"`
+	SHA1     string `json:"sha1"`
+	SHA256   string `json:"sha256"`
+	MimeType string `json:"mimeType"`
+}
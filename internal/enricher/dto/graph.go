@@ -0,0 +1,31 @@
+package dto
+
+import "fmt"
+
+type Observable struct {
+	Value string
+	Type  EnricherArgType
+}
+
+func (observable Observable) ID() string {
+	return fmt.Sprintf("%s:%s", observable.Type, observable.Value)
+}
+
+type ObservableNode struct {
+	ID       string
+	Value    string
+	Type     EnricherArgType
+	Depth    int
+	Enriched bool
+}
+
+type ObservableEdge struct {
+	From     string
+	To       string
+	Enricher string
+}
+
+type EnrichmentGraph struct {
+	Nodes []ObservableNode
+	Edges []ObservableEdge
+}
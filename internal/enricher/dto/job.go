@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobPending            JobStatus = "pending"
+	JobRunning            JobStatus = "running"
+	JobSucceeded          JobStatus = "succeeded"
+	JobPartiallySucceeded JobStatus = "partially_succeeded"
+	JobFailed             JobStatus = "failed"
+)
+
+type EnricherProgress struct {
+	Enricher   string
+	Observable string
+	DataType   EnricherArgType
+	Status     JobStatus
+}
+
+type Job struct {
+	ID         string
+	Status     JobStatus
+	Input      EnricherInputData
+	CreatedAt  time.Time
+	StartedAt  *time.Time `json:",omitempty"`
+	FinishedAt *time.Time `json:",omitempty"`
+	Progress   []EnricherProgress
+	Results    []EnricherResultEnvelope `json:",omitempty"`
+	Graph      *EnrichmentGraph         `json:",omitempty"`
+	Batch      *BatchSummary            `json:",omitempty"`
+	Errors     []string                 `json:",omitempty"`
+}
+
+type JobResults struct {
+	ID      string
+	Status  JobStatus
+	Results []EnricherResultEnvelope
+	Graph   *EnrichmentGraph `json:",omitempty"`
+	Batch   *BatchSummary    `json:",omitempty"`
+	Errors  []string
+}
+
+func (job Job) IsFinished() bool {
+	return job.Status == JobSucceeded || job.Status == JobPartiallySucceeded || job.Status == JobFailed
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type EnricherLoadEntry struct {
+	Directory    string
+	Enricher     string `json:",omitempty"`
+	Reason       string `json:",omitempty"`
+	KeptPrevious bool   `json:",omitempty"`
+}
+
+// EnricherLoadReport describes the last load. Rejected is set when the load
+// was not applied, the previously loaded enrichers are then still in use.
+type EnricherLoadReport struct {
+	LoadedAt time.Time
+	Loaded   []EnricherLoadEntry
+	Skipped  []EnricherLoadEntry
+	Invalid  []EnricherLoadEntry
+	Rejected string `json:",omitempty"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type ObservableTypeDefinition struct {
+	Name       EnricherArgType `json:"name"`
+	Pattern    string          `json:"pattern"`
+	Normalize  []string        `json:"normalize,omitempty"`
+	Confidence float64         `json:"confidence,omitempty"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type EnricherOverride struct {
+	Enabled  *bool  `json:"enabled,omitempty"`
+	Timeout  *int64 `json:"timeout,omitempty"`
+	CacheTTL *int64 `json:"cacheTTL,omitempty"`
+}
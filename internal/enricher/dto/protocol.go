@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type EnricherProtocol string
+
+const (
+	ArgvProtocol EnricherProtocol = "argv"
+	JSONProtocol EnricherProtocol = "json"
+)
+
+const JSONProtocolVersion = 1
+
+type EnricherRequestEnvelope struct {
+	Version    int                       `json:"version"`
+	Observable string                    `json:"observable"`
+	DataType   EnricherArgType           `json:"dataType"`
+	File       *FileAttributes           `json:"file,omitempty"`
+	Args       map[string]any            `json:"args"`
+	JobID      string                    `json:"jobId,omitempty"`
+	Deadline   *time.Time                `json:"deadline,omitempty"`
+	Upstream   map[string]EnricherResult `json:"upstream,omitempty"`
+}
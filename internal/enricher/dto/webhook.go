@@ -0,0 +1,17 @@
+package dto
+
+type WebhookKind string
+
+const (
+	GraphWebhook        WebhookKind = "graph"
+	BatchSummaryWebhook WebhookKind = "batch_summary"
+)
+
+// WebhookNotification wraps the webhook payloads other than the result
+// envelopes, Kind tells receivers which of the payload fields is set.
+type WebhookNotification struct {
+	Kind  WebhookKind
+	JobID string
+	Graph *EnrichmentGraph `json:",omitempty"`
+	Batch *BatchSummary    `json:",omitempty"`
+}
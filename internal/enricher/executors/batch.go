@@ -0,0 +1,41 @@
+package executors
+
+import (
+	"context"
+	"enricher/internal/common"
+	"enricher/internal/enricher/dto"
+)
+
+// BatchFunc is called once the enrichment of an observable of a batch is
+// done, index being its position in the batch.
+type BatchFunc func(index int, results []dto.EnricherResultEnvelope, err error)
+
+// ExecuteBatch enriches every observable with the request settings of
+// enricherData, at most Batch.Concurrency of them at once. Their enricher
+// processes count against the executor wide process limit like those of
+// any other request. Batches are not pivoted. Outcomes keep the order of
+// the observables.
+func (executor EnricherExecutorService) ExecuteBatch(ctx context.Context, enricherData dto.EnricherInputData, batch []dto.Observable, done BatchFunc) []common.Outcome[[]dto.EnricherResultEnvelope] {
+	indexes := make([]int, len(batch))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	processor := func(ctx context.Context, index int, enricherData dto.EnricherInputData) ([]dto.EnricherResultEnvelope, error) {
+		results, err := executor.ExecuteEnrichers(ctx, getBatchInputData(enricherData, batch[index]), nil)
+		if done != nil {
+			done(index, results, err)
+		}
+		return results, err
+	}
+
+	return common.ParallelExecute(ctx, enricherData, indexes, processor, executor.config.Batch.Concurrency)
+}
+
+func getBatchInputData(enricherData dto.EnricherInputData, observable dto.Observable) dto.EnricherInputData {
+	enricherData.Data = observable.Value
+	enricherData.DataType = observable.Type
+	enricherData.File = nil
+
+	return enricherData
+}
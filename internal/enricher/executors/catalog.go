@@ -0,0 +1,117 @@
+package executors
+
+import (
+	"enricher/internal/enricher/args"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrEnricherNotFound = errors.New("enricher not found")
+
+type EnricherCatalog interface {
+	ListEnrichers() []dto.EnricherInfo
+	GetEnricherInfo(name string) (dto.EnricherInfo, error)
+}
+
+func (executor EnricherExecutorService) ListEnrichers() []dto.EnricherInfo {
+	seen := make(map[string]bool)
+	enrichersInfo := []dto.EnricherInfo{}
+
+	for _, enrichers := range executor.enrichers.Enrichers() {
+		for _, enricher := range enrichers {
+			if seen[enricher.Name] {
+				continue
+			}
+			seen[enricher.Name] = true
+			enrichersInfo = append(enrichersInfo, executor.getEnricherInfo(enricher))
+		}
+	}
+
+	sort.Slice(enrichersInfo, func(i, j int) bool {
+		return enrichersInfo[i].Name < enrichersInfo[j].Name
+	})
+
+	return enrichersInfo
+}
+
+func (executor EnricherExecutorService) GetEnricherInfo(name string) (dto.EnricherInfo, error) {
+	for _, enrichers := range executor.enrichers.Enrichers() {
+		for _, enricher := range enrichers {
+			if enricher.Name == name {
+				return executor.getEnricherInfo(enricher), nil
+			}
+		}
+	}
+
+	return dto.EnricherInfo{}, ErrEnricherNotFound
+}
+
+func (executor EnricherExecutorService) getEnricherInfo(enricher dto.Enricher) dto.EnricherInfo {
+	cacheTTL := int64(0)
+	if !enricher.DisableCache {
+		cacheTTL = enricher.CacheTTL
+		if cacheTTL <= 0 {
+			cacheTTL = int64(executor.defaultCacheTTL)
+		}
+	}
+
+	return dto.EnricherInfo{
+		Name:           enricher.Name,
+		Version:        enricher.Version,
+		Description:    enricher.Description,
+		Author:         enricher.Author,
+		Source:         enricher.Source,
+		AllowedTypes:   enricher.AllowedTypes,
+		Tags:           enricher.Tags,
+		DependsOn:      enricher.DependsOn,
+		ConfigArgs:     maskSecretArgs(enricher.ConfigArgs),
+		Enabled:        enricher.Enabled,
+		Timeout:        int64(getExecutionTimeout(enricher, executor.config).Seconds()),
+		CacheTTL:       cacheTTL,
+		CacheTTLByType: enricher.CacheTTLByType,
+		ResultSchema:   enricher.ResultSchema,
+		SchemaPolicy:   executor.getSchemaPolicy(enricher),
+		Stats:          executor.stats.get(enricher.Name),
+	}
+}
+
+func maskSecretArgs(configArgs []dto.EnricherConfigArg) []dto.EnricherConfigArg {
+	masked := make([]dto.EnricherConfigArg, len(configArgs))
+	for i, arg := range configArgs {
+		masked[i] = arg
+		if arg.Secret && arg.DefaultValue != nil {
+			masked[i].DefaultValue = dto.MaskedValue
+		}
+	}
+	return masked
+}
+
+// MaskSecretArgs replaces the request values of the args declared secret.
+// Jobs keep the request args to be resumed, but never return them.
+func (executor EnricherExecutorService) MaskSecretArgs(requestArgs map[string]map[string]any) map[string]map[string]any {
+	if len(requestArgs) == 0 {
+		return requestArgs
+	}
+
+	enrichersByName := make(map[string]dto.Enricher)
+	for _, enrichers := range executor.enrichers.Enrichers() {
+		for _, enricher := range enrichers {
+			enrichersByName[strings.ToLower(enricher.Name)] = enricher
+		}
+	}
+
+	masked := make(map[string]map[string]any, len(requestArgs))
+	for enricherName, values := range requestArgs {
+		enricher := enrichersByName[strings.ToLower(enricherName)]
+		masked[enricherName] = make(map[string]any, len(values))
+		for name, value := range values {
+			if args.IsSecret(enricher, name) {
+				value = dto.MaskedValue
+			}
+			masked[enricherName][name] = value
+		}
+	}
+	return masked
+}
@@ -0,0 +1,64 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"reflect"
+	"testing"
+)
+
+type staticEnrichers map[dto.EnricherArgType][]dto.Enricher
+
+func (enrichers staticEnrichers) Enrichers() map[dto.EnricherArgType][]dto.Enricher {
+	return enrichers
+}
+
+func TestMaskSecretArgs(t *testing.T) {
+	executor := EnricherExecutorService{enrichers: staticEnrichers{
+		dto.DOMAIN: {{
+			Name: "whois",
+			ConfigArgs: []dto.EnricherConfigArg{
+				{Name: "apiKey", Type: dto.StringArg, Secret: true},
+				{Name: "maxResults", Type: dto.IntArg},
+			},
+		}},
+	}}
+
+	tests := []struct {
+		name        string
+		requestArgs map[string]map[string]any
+		want        map[string]map[string]any
+	}{
+		{
+			name: "no args",
+		},
+		{
+			name:        "secret arg",
+			requestArgs: map[string]map[string]any{"whois": {"apiKey": "secret", "maxResults": 5}},
+			want:        map[string]map[string]any{"whois": {"apiKey": dto.MaskedValue, "maxResults": 5}},
+		},
+		{
+			name:        "names spelled differently",
+			requestArgs: map[string]map[string]any{"WHOIS": {"APIKEY": "secret"}},
+			want:        map[string]map[string]any{"WHOIS": {"APIKEY": dto.MaskedValue}},
+		},
+		{
+			name:        "unknown enricher",
+			requestArgs: map[string]map[string]any{"dns": {"apiKey": "value"}},
+			want:        map[string]map[string]any{"dns": {"apiKey": "value"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executor.MaskSecretArgs(tt.requestArgs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaskSecretArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	requestArgs := map[string]map[string]any{"whois": {"apiKey": "secret"}}
+	executor.MaskSecretArgs(requestArgs)
+	if value := requestArgs["whois"]["apiKey"]; value != "secret" {
+		t.Errorf("MaskSecretArgs() modified the request args: %v", value)
+	}
+}
@@ -1,40 +1,271 @@
 package executors
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"enricher/configs"
+	"enricher/internal/enricher/args"
 	"enricher/internal/enricher/cache"
 	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
+const (
+	processWaitDelay = time.Second
+	upstreamEnvName  = "ENRICHER_UPSTREAM_FILE"
+)
+
+var ErrEnricherTimeout = errors.New("timeout")
+
+// pluginEnvNames are the only service environment variables passed to the
+// enricher executables, the ENRICHER_<ENRICHER>_<ARG> secrets of the other
+// enrichers are never inherited.
+var pluginEnvNames = []string{
+	"PATH", "HOME", "TMPDIR", "TEMP", "TMP", "USER", "LANG", "LC_ALL", "TZ",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR", "SYSTEMROOT",
+}
+
 type EnricherCmdExecutorService EnricherExecutorService
 
-func NewEnricherCmdExecutorService(cacheClient *cache.CacheClient, enrichers map[dto.EnricherArgType][]dto.Enricher) *EnricherCmdExecutorService {
+func NewEnricherCmdExecutorService(cacheClient *cache.CacheClient, enrichers EnricherProvider, config configs.ExecutorConfig, cacheConfig configs.CacheConfig, profiles map[string]configs.ProfileConfig) *EnricherCmdExecutorService {
 	execService := &EnricherCmdExecutorService{
-		processor:   CmdExecute,
-		enrichers:   enrichers,
-		cacheClient: *cacheClient,
+		processor: func(ctx context.Context, enricher dto.Enricher, enricherData dto.EnricherInputData) (dto.EnricherResultEnvelope, error) {
+			return CmdExecute(ctx, enricher, enricherData, getExecutionTimeout(enricher, config))
+		},
+		enrichers:       enrichers,
+		cacheClient:     *cacheClient,
+		config:          config,
+		defaultCacheTTL: cacheConfig.DefaultTTL,
+		maxConcurrency:  config.MaxConcurrency,
+		processes:       make(chan struct{}, config.MaxConcurrency),
+		profiles:        profiles,
+		stats:           newEnricherStats(),
 	}
 
 	return execService
 }
 
-func CmdExecute(enricher dto.Enricher, enricherData dto.EnricherInputData) (dto.EnricherResult, error) {
+func getExecutionTimeout(enricher dto.Enricher, config configs.ExecutorConfig) time.Duration {
+	timeout := enricher.Timeout
+	if timeout <= 0 {
+		timeout = int64(config.DefaultTimeout)
+	}
+	if config.MaxTimeout > 0 && timeout > int64(config.MaxTimeout) {
+		timeout = int64(config.MaxTimeout)
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// CmdExecute runs the enricher executable using its declared protocol.
+//
+// The executable only inherits the pluginEnvNames environment variables.
+//
+// With the argv protocol (default) the observable is the only argument,
+// config args are passed as ENRICHER_ARG_<NAME> environment variables, the
+// results of the dependencies in the JSON file named by
+// ENRICHER_UPSTREAM_FILE and the attributes of an
+// uploaded file as ENRICHER_FILE_<ATTRIBUTE>.
+//
+// With the json protocol a dto.EnricherRequestEnvelope is written to stdin,
+// the dto.EnricherResult is read from stdout and stderr is logged.
+//
+// The whole process group is killed once the timeout expires or ctx is done.
+func CmdExecute(parent context.Context, enricher dto.Enricher, enricherData dto.EnricherInputData, timeout time.Duration) (envelope dto.EnricherResultEnvelope, err error) {
 	log.Printf("Execute enricher: %s", enricher.Name)
-	path := enricher.ExecutablePath
 
-	cmd := exec.Command(path, enricherData.Data)
+	envelope = dto.EnricherResultEnvelope{
+		Enricher:   enricher.Name,
+		Version:    enricher.Version,
+		Source:     enricher.Source,
+		Observable: enricherData.Data,
+		DataType:   enricherData.DataType,
+		File:       enricherData.File,
+		StartedAt:  time.Now(),
+		ExitCode:   -1,
+	}
+	defer func() {
+		envelope.FinishedAt = time.Now()
+		envelope.Duration = envelope.FinishedAt.Sub(envelope.StartedAt).Milliseconds()
+	}()
+
+	argValues, err := args.Merge(enricher, args.Configured(enricherData.Args, enricher.Name))
+	if err != nil {
+		log.Printf("Resolve enricher %s arguments error: %v", enricher.Name, err)
+		return envelope, err
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	switch enricher.Protocol {
+	case dto.JSONProtocol:
+		envelope.Result, envelope.ExitCode, err = executeJSONProtocol(ctx, enricher, enricherData, argValues)
+	default:
+		envelope.Result, envelope.ExitCode, err = executeArgvProtocol(ctx, enricher, enricherData, argValues)
+	}
+
+	if parent.Err() != nil {
+		err = fmt.Errorf("enricher %s interrupted: %w", enricher.Name, parent.Err())
+		log.Print(err)
+		envelope.Result = dto.EnricherResult{Errors: []string{err.Error()}}
+	} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("enricher %s: %w after %s", enricher.Name, ErrEnricherTimeout, timeout)
+		log.Print(err)
+		envelope.Result = dto.EnricherResult{Errors: []string{err.Error()}}
+	}
+
+	return envelope, err
+}
+
+func newCmd(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.WaitDelay = processWaitDelay
+	configureProcessGroup(cmd)
+
+	return cmd
+}
+
+func getExitCode(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+func executeArgvProtocol(ctx context.Context, enricher dto.Enricher, enricherData dto.EnricherInputData, argValues map[string]any) (dto.EnricherResult, int, error) {
+	environ, cleanup, err := upstreamEnviron(getEnricherUpstream(enricher, enricherData.Upstream))
+	if err != nil {
+		log.Printf("Write upstream results error: %v", err)
+		return dto.EnricherResult{}, -1, err
+	}
+	defer cleanup()
+
+	cmd := newCmd(ctx, enricher.ExecutablePath, enricherData.Data)
+	cmd.Env = append(append(baseEnviron(), args.Environ(argValues)...), environ...)
+	cmd.Env = append(cmd.Env, fileEnviron(enricherData.File)...)
 
 	output, err := cmd.CombinedOutput()
+	exitCode := getExitCode(cmd)
 
 	if err != nil {
 		log.Printf("Execute cmd error: %v, %s", err, output)
-		return dto.EnricherResult{}, err
+		return dto.EnricherResult{}, exitCode, err
+	}
+
+	result, err := decodeEnricherOutput(output)
+	return result, exitCode, err
+}
+
+func executeJSONProtocol(ctx context.Context, enricher dto.Enricher, enricherData dto.EnricherInputData, argValues map[string]any) (dto.EnricherResult, int, error) {
+	envelope := dto.EnricherRequestEnvelope{
+		Version:    dto.JSONProtocolVersion,
+		Observable: enricherData.Data,
+		DataType:   enricherData.DataType,
+		File:       enricherData.File,
+		Args:       argValues,
+		JobID:      enricherData.JobID,
+		Upstream:   getEnricherUpstream(enricher, enricherData.Upstream),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		envelope.Deadline = &deadline
 	}
 
+	input, err := json.Marshal(envelope)
+	if err != nil {
+		log.Printf("Marshal request envelope error: %v", err)
+		return dto.EnricherResult{}, -1, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := newCmd(ctx, enricher.ExecutablePath)
+	cmd.Env = baseEnviron()
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	exitCode := getExitCode(cmd)
+	logEnricherStderr(enricher, stderr.String())
+
+	if err != nil {
+		log.Printf("Execute cmd error: %v", err)
+		return dto.EnricherResult{}, exitCode, err
+	}
+
+	result, err := decodeEnricherOutput(stdout.Bytes())
+	return result, exitCode, err
+}
+
+func baseEnviron() []string {
+	var environ []string
+	for _, name := range pluginEnvNames {
+		if value, found := os.LookupEnv(name); found {
+			environ = append(environ, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	return environ
+}
+
+// upstreamEnviron passes the results of the enricher dependencies to the
+// argv protocol as a JSON object keyed by enricher name. Reports easily
+// exceed the size limit of a single environment variable, so they are
+// written to a temporary file removed by cleanup.
+func upstreamEnviron(upstream map[string]dto.EnricherResult) ([]string, func(), error) {
+	if len(upstream) == 0 {
+		return nil, func() {}, nil
+	}
+
+	value, err := json.Marshal(upstream)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.CreateTemp("", "enricher-upstream-*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	_, err = file.Write(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return []string{fmt.Sprintf("%s=%s", upstreamEnvName, file.Name())}, cleanup, nil
+}
+
+// fileEnviron passes the attributes of an uploaded file to the argv
+// protocol, the file path itself is the observable argument.
+func fileEnviron(file *dto.FileAttributes) []string {
+	if file == nil {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("ENRICHER_FILE_NAME=%s", file.Name),
+		fmt.Sprintf("ENRICHER_FILE_SIZE=%d", file.Size),
+		fmt.Sprintf("ENRICHER_FILE_MD5=%s", file.MD5),
+		fmt.Sprintf("ENRICHER_FILE_SHA1=%s", file.SHA1),
+		fmt.Sprintf("ENRICHER_FILE_SHA256=%s", file.SHA256),
+		fmt.Sprintf("ENRICHER_FILE_MIME_TYPE=%s", file.MimeType),
+	}
+}
+
+func decodeEnricherOutput(output []byte) (dto.EnricherResult, error) {
 	var result dto.EnricherResult
-	err = json.Unmarshal(output, &result)
+	err := json.Unmarshal(output, &result)
 
 	if err != nil {
 		log.Printf("Unmarshal error: %v", err)
@@ -43,3 +274,11 @@ func CmdExecute(enricher dto.Enricher, enricherData dto.EnricherInputData) (dto.
 
 	return result, nil
 }
+
+func logEnricherStderr(enricher dto.Enricher, stderr string) {
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		if line != "" {
+			log.Printf("Enricher %s: %s", enricher.Name, line)
+		}
+	}
+}
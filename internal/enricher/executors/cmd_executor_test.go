@@ -0,0 +1,262 @@
+//go:build !windows
+
+package executors
+
+import (
+	"context"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeScript writes an executable shell script enricher.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "enricher.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCmdExecuteTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       string
+		timeout      time.Duration
+		cancelAfter  time.Duration
+		wantErr      error
+		wantExitCode int
+	}{
+		{
+			name:         "finishes in time",
+			script:       `echo '{"Report": {"ok": true}}'`,
+			timeout:      5 * time.Second,
+			wantExitCode: 0,
+		},
+		{
+			name:         "killed after the timeout",
+			script:       `sleep 30`,
+			timeout:      200 * time.Millisecond,
+			wantErr:      ErrEnricherTimeout,
+			wantExitCode: -1,
+		},
+		{
+			name:         "interrupted by the caller",
+			script:       `sleep 30`,
+			timeout:      5 * time.Second,
+			cancelAfter:  200 * time.Millisecond,
+			wantErr:      context.Canceled,
+			wantExitCode: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			enricher := dto.Enricher{Name: "script", ExecutablePath: writeScript(t, tt.script)}
+			started := time.Now()
+			envelope, err := CmdExecute(ctx, enricher, dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN}, tt.timeout)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CmdExecute() error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(started); elapsed > 5*time.Second {
+				t.Errorf("CmdExecute() took %s", elapsed)
+			}
+			if envelope.ExitCode != tt.wantExitCode {
+				t.Errorf("CmdExecute() exit code = %d, want %d", envelope.ExitCode, tt.wantExitCode)
+			}
+			if tt.wantErr != nil && (len(envelope.Result.Errors) != 1 || envelope.Result.Errors[0] != err.Error()) {
+				t.Errorf("CmdExecute() result errors = %v, want %q", envelope.Result.Errors, err)
+			}
+		})
+	}
+}
+
+func TestCmdExecuteKillsProcessGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol dto.EnricherProtocol
+	}{
+		{"argv protocol", dto.ArgvProtocol},
+		{"json protocol", dto.JSONProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pidFile := filepath.Join(t.TempDir(), "child.pid")
+			// the child keeps running unless the whole group is killed
+			script := writeScript(t, `sleep 30 & echo $! > `+pidFile+`; wait`)
+
+			enricher := dto.Enricher{Name: "spawner", ExecutablePath: script, Protocol: tt.protocol}
+			_, err := CmdExecute(context.Background(), enricher, dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN}, 300*time.Millisecond)
+			if !errors.Is(err, ErrEnricherTimeout) {
+				t.Fatalf("CmdExecute() error = %v, want %v", err, ErrEnricherTimeout)
+			}
+
+			content, err := os.ReadFile(pidFile)
+			if err != nil {
+				t.Fatalf("child pid not written: %v", err)
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deadline := time.Now().Add(2 * time.Second)
+			for processRunning(pid) {
+				if time.Now().After(deadline) {
+					syscall.Kill(pid, syscall.SIGKILL)
+					t.Fatalf("child process %d is still running", pid)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		})
+	}
+}
+
+// processRunning treats zombies as stopped, the killed child may not be
+// reaped by the init process of a container.
+func processRunning(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
+func TestCmdExecuteJSONProtocol(t *testing.T) {
+	enricherData := dto.EnricherInputData{
+		Data:     "example.com",
+		DataType: dto.DOMAIN,
+		JobID:    "job-1",
+		Args:     map[string]map[string]any{"script": {"maxResults": "5"}},
+		Upstream: map[string]dto.EnricherResult{"dns": {Report: map[string]any{"a": "1.1.1.1"}}},
+	}
+
+	tests := []struct {
+		name         string
+		script       string
+		wantErr      bool
+		wantExitCode int
+		wantReport   map[string]any
+	}{
+		{
+			name:         "request envelope on stdin",
+			script:       `echo "diagnostics" >&2; printf '{"Report": {"request": %s}}' "$(cat)"`,
+			wantExitCode: 0,
+			wantReport: map[string]any{
+				"version":    float64(dto.JSONProtocolVersion),
+				"observable": "example.com",
+				"dataType":   "DOMAIN",
+				"args":       map[string]any{"maxResults": float64(5)},
+				"jobId":      "job-1",
+				"upstream":   map[string]any{"dns": map[string]any{"Report": map[string]any{"a": "1.1.1.1"}, "Errors": nil}},
+			},
+		},
+		{
+			name:         "failing executable",
+			script:       `cat > /dev/null; echo '{"Report": {}}'; exit 3`,
+			wantErr:      true,
+			wantExitCode: 3,
+		},
+		{
+			name:         "invalid result",
+			script:       `cat > /dev/null; echo 'not json'`,
+			wantErr:      true,
+			wantExitCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher := dto.Enricher{
+				Name:           "script",
+				ExecutablePath: writeScript(t, tt.script),
+				Protocol:       dto.JSONProtocol,
+				DependsOn:      []string{"dns"},
+				ConfigArgs:     []dto.EnricherConfigArg{{Name: "maxResults", Type: dto.IntArg}},
+			}
+
+			envelope, err := CmdExecute(context.Background(), enricher, enricherData, 5*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CmdExecute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if envelope.ExitCode != tt.wantExitCode {
+				t.Errorf("CmdExecute() exit code = %d, want %d", envelope.ExitCode, tt.wantExitCode)
+			}
+			if tt.wantReport == nil {
+				return
+			}
+
+			request, _ := envelope.Result.Report["request"].(map[string]any)
+			if _, exists := request["deadline"]; !exists {
+				t.Errorf("request envelope has no deadline: %v", request)
+			}
+			delete(request, "deadline")
+			if !reflect.DeepEqual(request, tt.wantReport) {
+				t.Errorf("request envelope = %v, want %v", request, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestCmdExecuteArgvEnvironment(t *testing.T) {
+	t.Setenv("TZ", "UTC")
+	t.Setenv("ENRICHER_OTHER_APIKEY", "other secret")
+	t.Setenv("DATABASE_PASSWORD", "service secret")
+
+	enricher := dto.Enricher{
+		Name: "script",
+		ExecutablePath: writeScript(t, `printf '{"Report": {"observable": "%s", "apiKey": "%s", "tz": "%s", "other": "%s", "service": "%s"}}' `+
+			`"$1" "$ENRICHER_ARG_APIKEY" "${TZ-unset}" "${ENRICHER_OTHER_APIKEY-unset}" "${DATABASE_PASSWORD-unset}"`),
+		ConfigArgs: []dto.EnricherConfigArg{{Name: "apiKey", Type: dto.StringArg, Secret: true}},
+		Args:       map[string]any{"apiKey": "config"},
+	}
+
+	tests := []struct {
+		name       string
+		args       map[string]map[string]any
+		wantReport map[string]any
+	}{
+		{
+			name:       "config args",
+			wantReport: map[string]any{"observable": "example.com", "apiKey": "config", "tz": "UTC", "other": "unset", "service": "unset"},
+		},
+		{
+			name:       "request args",
+			args:       map[string]map[string]any{"SCRIPT": {"apikey": "request"}},
+			wantReport: map[string]any{"observable": "example.com", "apiKey": "request", "tz": "UTC", "other": "unset", "service": "unset"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := CmdExecute(context.Background(), enricher, dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, Args: tt.args}, 5*time.Second)
+			if err != nil {
+				t.Fatalf("CmdExecute() error = %v", err)
+			}
+			if !reflect.DeepEqual(envelope.Result.Report, tt.wantReport) {
+				t.Errorf("CmdExecute() report = %v, want %v", envelope.Result.Report, tt.wantReport)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"fmt"
+	"slices"
+)
+
+// withDependencies adds the enabled dependencies of the selected enrichers,
+// unless they are excluded, keeping the order of the enabled enrichers.
+func withDependencies(selected []dto.Enricher, enabled []dto.Enricher, exclude ...[]string) []dto.Enricher {
+	enabledByName := make(map[string]dto.Enricher, len(enabled))
+	for _, enricher := range enabled {
+		enabledByName[enricher.Name] = enricher
+	}
+
+	required := make(map[string]bool)
+	queue := append([]dto.Enricher(nil), selected...)
+	for len(queue) > 0 {
+		enricher := queue[0]
+		queue = queue[1:]
+		if required[enricher.Name] {
+			continue
+		}
+		required[enricher.Name] = true
+
+		for _, dependencyName := range enricher.DependsOn {
+			dependency, exists := enabledByName[dependencyName]
+			if exists && !isExcluded(dependencyName, exclude...) {
+				queue = append(queue, dependency)
+			}
+		}
+	}
+
+	var enrichers []dto.Enricher
+	for _, enricher := range enabled {
+		if required[enricher.Name] {
+			enrichers = append(enrichers, enricher)
+		}
+	}
+	return enrichers
+}
+
+func isExcluded(name string, exclude ...[]string) bool {
+	for _, list := range exclude {
+		if slices.Contains(list, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDependencyWaves splits the enrichers into waves, every enricher runs
+// after the waves of its dependencies. Dependencies which are not among
+// the enrichers do not delay it.
+func getDependencyWaves(enrichers []dto.Enricher) [][]dto.Enricher {
+	pending := make(map[string]bool, len(enrichers))
+	for _, enricher := range enrichers {
+		pending[enricher.Name] = true
+	}
+
+	var waves [][]dto.Enricher
+	remaining := enrichers
+	for len(remaining) > 0 {
+		var wave, next []dto.Enricher
+		for _, enricher := range remaining {
+			if dependenciesPending(enricher, pending) {
+				next = append(next, enricher)
+			} else {
+				wave = append(wave, enricher)
+			}
+		}
+		if len(wave) == 0 {
+			wave, next = next, nil
+		}
+
+		for _, enricher := range wave {
+			delete(pending, enricher.Name)
+		}
+		waves = append(waves, wave)
+		remaining = next
+	}
+
+	return waves
+}
+
+func dependenciesPending(enricher dto.Enricher, pending map[string]bool) bool {
+	for _, dependencyName := range enricher.DependsOn {
+		if pending[dependencyName] {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDependencies(enricher dto.Enricher, upstream map[string]dto.EnricherResultEnvelope) error {
+	for _, dependencyName := range enricher.DependsOn {
+		result, exists := upstream[dependencyName]
+		if !exists {
+			return fmt.Errorf("dependency %s was not executed", dependencyName)
+		}
+		if result.Status != dto.JobSucceeded {
+			return fmt.Errorf("dependency %s did not succeed", dependencyName)
+		}
+	}
+	return nil
+}
+
+func getUpstreamResults(upstream map[string]dto.EnricherResultEnvelope) map[string]dto.EnricherResult {
+	results := make(map[string]dto.EnricherResult, len(upstream))
+	for name, envelope := range upstream {
+		if envelope.Status == dto.JobSucceeded {
+			results[name] = envelope.Result
+		}
+	}
+	return results
+}
+
+func getEnricherUpstream(enricher dto.Enricher, upstream map[string]dto.EnricherResult) map[string]dto.EnricherResult {
+	if len(enricher.DependsOn) == 0 {
+		return nil
+	}
+
+	results := make(map[string]dto.EnricherResult, len(enricher.DependsOn))
+	for _, dependencyName := range enricher.DependsOn {
+		if result, exists := upstream[dependencyName]; exists {
+			results[dependencyName] = result
+		}
+	}
+	return results
+}
@@ -0,0 +1,121 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"slices"
+	"testing"
+)
+
+func TestGetDependencyWaves(t *testing.T) {
+	tests := []struct {
+		name      string
+		enrichers []dto.Enricher
+		want      [][]string
+	}{
+		{
+			name: "no enrichers",
+		},
+		{
+			name:      "independent enrichers share a wave",
+			enrichers: []dto.Enricher{{Name: "dns"}, {Name: "whois"}},
+			want:      [][]string{{"dns", "whois"}},
+		},
+		{
+			name: "chain",
+			enrichers: []dto.Enricher{
+				{Name: "verdict", DependsOn: []string{"geo"}},
+				{Name: "geo", DependsOn: []string{"dns"}},
+				{Name: "dns"},
+			},
+			want: [][]string{{"dns"}, {"geo"}, {"verdict"}},
+		},
+		{
+			name: "diamond",
+			enrichers: []dto.Enricher{
+				{Name: "dns"},
+				{Name: "geo", DependsOn: []string{"dns"}},
+				{Name: "whois", DependsOn: []string{"dns"}},
+				{Name: "verdict", DependsOn: []string{"geo", "whois"}},
+				{Name: "reputation"},
+			},
+			want: [][]string{{"dns", "reputation"}, {"geo", "whois"}, {"verdict"}},
+		},
+		{
+			name: "dependency not selected does not delay",
+			enrichers: []dto.Enricher{
+				{Name: "geo", DependsOn: []string{"dns"}},
+				{Name: "whois"},
+			},
+			want: [][]string{{"geo", "whois"}},
+		},
+		{
+			name: "cycle runs in a single wave",
+			enrichers: []dto.Enricher{
+				{Name: "dns"},
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+			},
+			want: [][]string{{"dns"}, {"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waves := getDependencyWaves(tt.enrichers)
+
+			got := make([][]string, 0, len(waves))
+			for _, wave := range waves {
+				names := make([]string, 0, len(wave))
+				for _, enricher := range wave {
+					names = append(names, enricher.Name)
+				}
+				got = append(got, names)
+			}
+
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("getDependencyWaves() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDependencies(t *testing.T) {
+	enabled := []dto.Enricher{
+		{Name: "dns"},
+		{Name: "geo", DependsOn: []string{"dns"}},
+		{Name: "whois"},
+		{Name: "verdict", DependsOn: []string{"geo", "whois"}},
+	}
+
+	tests := []struct {
+		name     string
+		selected []string
+		exclude  []string
+		want     []string
+	}{
+		{"without dependencies", []string{"whois"}, nil, []string{"whois"}},
+		{"transitive dependencies in enabled order", []string{"verdict"}, nil, []string{"dns", "geo", "whois", "verdict"}},
+		{"excluded dependency", []string{"verdict"}, []string{"geo"}, []string{"whois", "verdict"}},
+		{"excluded selected enricher is kept", []string{"geo"}, []string{"geo"}, []string{"dns", "geo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selected []dto.Enricher
+			for _, enricher := range enabled {
+				if slices.Contains(tt.selected, enricher.Name) {
+					selected = append(selected, enricher)
+				}
+			}
+
+			var got []string
+			for _, enricher := range withDependencies(selected, enabled, tt.exclude) {
+				got = append(got, enricher.Name)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("withDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,32 +1,90 @@
 package executors
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"enricher/configs"
 	"enricher/internal/common"
+	"enricher/internal/enricher/args"
 	"enricher/internal/enricher/cache"
 	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
 	"errors"
 	"fmt"
 	"log"
+	"time"
 )
 
 type EnricherExecutor interface {
-	ExecuteEnrichers(enricherData dto.EnricherInputData) ([]dto.EnricherResult, error)
+	SelectEnrichers(enricherData dto.EnricherInputData) ([]dto.Enricher, error)
+	ExecuteEnrichers(ctx context.Context, enricherData dto.EnricherInputData, progress ProgressFunc) ([]dto.EnricherResultEnvelope, error)
+	ExecuteGraph(ctx context.Context, enricherData dto.EnricherInputData, progress ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error)
+	ExecuteBatch(ctx context.Context, enricherData dto.EnricherInputData, batch []dto.Observable, done BatchFunc) []common.Outcome[[]dto.EnricherResultEnvelope]
+	MaskSecretArgs(requestArgs map[string]map[string]any) map[string]map[string]any
+}
+
+type ProgressFunc func(enricher dto.Enricher, observable dto.Observable, status dto.JobStatus)
+
+type EnricherProvider interface {
+	Enrichers() map[dto.EnricherArgType][]dto.Enricher
 }
 
 type EnricherExecutorService struct {
-	enrichers   map[dto.EnricherArgType][]dto.Enricher
-	cacheClient cache.CacheClient
-	processor   common.Processor[dto.Enricher, dto.EnricherInputData, dto.EnricherResult]
+	enrichers       EnricherProvider
+	cacheClient     cache.CacheClient
+	config          configs.ExecutorConfig
+	defaultCacheTTL int
+	maxConcurrency  int
+	processes       chan struct{}
+	profiles        map[string]configs.ProfileConfig
+	stats           *enricherStats
+	processor       common.Processor[dto.Enricher, dto.EnricherInputData, dto.EnricherResultEnvelope]
 }
 
-func (executor EnricherExecutorService) getEnrichmentResultCacheKey(enricher dto.Enricher, data dto.EnricherInputData) string {
-	return fmt.Sprintf("%s-%v-%s", enricher.Name, data.Data, data.DataType)
+// getEnrichmentResultCacheKey uses the SHA256 of uploaded files, so an
+// identical file is enriched once whatever temporary path it gets. The
+// effective args are hashed into the key, results of requests or profiles
+// with other args are never shared.
+func (executor EnricherExecutorService) getEnrichmentResultCacheKey(enricher dto.Enricher, data dto.EnricherInputData) (string, error) {
+	observable := data.Data
+	if data.File != nil {
+		observable = data.File.SHA256
+	}
+
+	argValues, err := args.Merge(enricher, args.Configured(data.Args, enricher.Name))
+	if err != nil {
+		return "", err
+	}
+	encodedArgs, err := json.Marshal(argValues)
+	if err != nil {
+		return "", err
+	}
+	argsHash := sha256.Sum256(encodedArgs)
+
+	return fmt.Sprintf("%s-%v-%s-%s", enricher.Name, observable, data.DataType, hex.EncodeToString(argsHash[:8])), nil
+}
+
+func (executor EnricherExecutorService) getEnrichmentResultCacheTTL(enricher dto.Enricher, data dto.EnricherInputData) int {
+	if enricher.DisableCache {
+		return 0
+	}
+	selectionType := getSelectionType(data)
+	for _, dataType := range []dto.EnricherArgType{selectionType, data.DataType, observables.Parent(selectionType)} {
+		if ttl, exists := enricher.CacheTTLByType[dataType]; exists && ttl > 0 {
+			return int(ttl)
+		}
+	}
+	if enricher.CacheTTL > 0 {
+		return int(enricher.CacheTTL)
+	}
+	return executor.defaultCacheTTL
 }
 
-func (executor EnricherExecutorService) decodeEnrichmentResult(data []byte) (dto.EnricherResult, error) {
+func (executor EnricherExecutorService) decodeEnrichmentResult(data []byte) (dto.EnricherResultEnvelope, error) {
 
-	var result dto.EnricherResult
+	var result dto.EnricherResultEnvelope
 	err := json.Unmarshal(data, &result)
 
 	return result, err
@@ -39,9 +97,17 @@ func (executor EnricherExecutorService) encodeEnrichmentResult(data interface{})
 	return value, err
 }
 
-func (executor EnricherExecutorService) putEnrichmentResultToCache(enricher dto.Enricher, data dto.EnricherInputData, result dto.EnricherResult) error {
+func (executor EnricherExecutorService) putEnrichmentResultToCache(enricher dto.Enricher, data dto.EnricherInputData, result dto.EnricherResultEnvelope) error {
+
+	ttl := executor.getEnrichmentResultCacheTTL(enricher, data)
+	if ttl <= 0 {
+		return nil
+	}
 
-	cacheKey := executor.getEnrichmentResultCacheKey(enricher, data)
+	cacheKey, err := executor.getEnrichmentResultCacheKey(enricher, data)
+	if err != nil {
+		return fmt.Errorf("enrichment result cache key error: %v", err)
+	}
 	cacheValue, err := executor.encodeEnrichmentResult(result)
 
 	if err != nil {
@@ -51,71 +117,197 @@ func (executor EnricherExecutorService) putEnrichmentResultToCache(enricher dto.
 	err = executor.cacheClient.SetWithTTL(
 		cacheKey,
 		cacheValue,
-		int(enricher.Timeout),
+		ttl,
 	)
 
 	return err
 }
 
-func (executor EnricherExecutorService) getEnrichmentResultFromCache(enricher dto.Enricher, data dto.EnricherInputData) (dto.EnricherResult, error) {
+func (executor EnricherExecutorService) getEnrichmentResultFromCache(enricher dto.Enricher, data dto.EnricherInputData) (dto.EnricherResultEnvelope, error) {
 
-	cacheKey := executor.getEnrichmentResultCacheKey(enricher, data)
+	if executor.getEnrichmentResultCacheTTL(enricher, data) <= 0 {
+		return dto.EnricherResultEnvelope{}, errors.New("enrichment result caching disabled")
+	}
+
+	cacheKey, err := executor.getEnrichmentResultCacheKey(enricher, data)
+	if err != nil {
+		return dto.EnricherResultEnvelope{}, err
+	}
 
 	value, err := executor.cacheClient.Get(cacheKey)
 
 	if err != nil {
 		log.Printf("Get enrichment result from cache error: %v", err)
-		return dto.EnricherResult{}, err
+		return dto.EnricherResultEnvelope{}, err
 	}
 
 	result, err := executor.decodeEnrichmentResult(value.([]byte))
 
 	if err != nil {
 		log.Printf("Decode enrichment result error: %v", err)
-		return dto.EnricherResult{}, err
+		return dto.EnricherResultEnvelope{}, err
 	}
+	result.Cached = true
+	result.Observable = data.Data
+	result.File = data.File
 
 	return result, err
 }
 
-func (executor EnricherExecutorService) ExecuteEnrichers(enricherData dto.EnricherInputData) ([]dto.EnricherResult, error) {
-	allowedEnrichersList, exists := executor.enrichers[enricherData.DataType]
-	if !exists {
-		errorMessage := fmt.Sprintf("Enricher for data type %s not found", string(enricherData.DataType))
-		return []dto.EnricherResult{}, errors.New(errorMessage)
+func (executor EnricherExecutorService) ExecuteEnrichers(ctx context.Context, enricherData dto.EnricherInputData, progress ProgressFunc) ([]dto.EnricherResultEnvelope, error) {
+	profile, err := executor.getProfile(enricherData.Profile)
+	if err != nil {
+		return []dto.EnricherResultEnvelope{}, err
 	}
 
-	enabledEnrichers := getEnabledEnrichers(allowedEnrichersList)
-
-	results := make([]dto.EnricherResult, 0, len(enabledEnrichers))
-	var errors []error
-	var enrichersToExecute []dto.Enricher
+	selectedEnrichers, err := executor.selectEnrichers(enricherData, profile)
+	if err != nil {
+		return []dto.EnricherResultEnvelope{}, err
+	}
+	enabledEnrichers, enricherData := applyProfile(profile, selectedEnrichers, enricherData)
 
+	observable := dto.Observable{Value: enricherData.Data, Type: enricherData.DataType}
+	notify := func(enricher dto.Enricher, status dto.JobStatus) {
+		if progress != nil {
+			progress(enricher, observable, status)
+		}
+	}
 	for _, enricher := range enabledEnrichers {
-		cachedResult, err := executor.getEnrichmentResultFromCache(enricher, enricherData)
+		notify(enricher, dto.JobPending)
+	}
+
+	results := make([]dto.EnricherResultEnvelope, 0, len(enabledEnrichers))
+	upstream := make(map[string]dto.EnricherResultEnvelope)
+
+	processor := func(ctx context.Context, enricher dto.Enricher, data dto.EnricherInputData) (dto.EnricherResultEnvelope, error) {
+		release, err := executor.acquireProcess(ctx)
+		if err != nil {
+			return dto.EnricherResultEnvelope{}, err
+		}
+		defer release()
+
+		notify(enricher, dto.JobRunning)
+		result, err := executor.processor(ctx, enricher, data)
+		result.Enricher = enricher.Name
 		if err == nil {
-			results = append(results, cachedResult)
+			err = executor.validateResult(enricher, &result)
+		}
+		if err != nil {
+			result.Status = dto.JobFailed
+			result.Error = err.Error()
 		} else {
-			enrichersToExecute = append(enrichersToExecute, enricher)
+			result.Status = dto.JobSucceeded
 		}
+		notify(enricher, result.Status)
+		return result, nil
 	}
 
-	if len(enrichersToExecute) > 0 {
-		parallelResults, err := common.ParallelExecute(enricherData, enrichersToExecute, executor.processor)
-		if err != nil {
-			errors = append(errors, err)
-		} else {
-			results = append(results, parallelResults...)
-			for i, enricher := range enrichersToExecute {
-				err := executor.putEnrichmentResultToCache(enricher, enricherData, parallelResults[i])
-				if err != nil {
-					log.Printf("Error putting enrichment result to cache: %v", err)
-				}
+	for _, wave := range getDependencyWaves(enabledEnrichers) {
+		var enrichersToExecute []dto.Enricher
+
+		for _, enricher := range wave {
+			if err := checkDependencies(enricher, upstream); err != nil {
+				result := newFailedResultEnvelope(enricher, enricherData, err)
+				notify(enricher, result.Status)
+				executor.stats.record(result)
+				results = append(results, result)
+				upstream[enricher.Name] = result
+				continue
+			}
+
+			cachedResult, err := executor.getEnrichmentResultFromCache(enricher, enricherData)
+			if err == nil {
+				results = append(results, cachedResult)
+				upstream[enricher.Name] = cachedResult
+				notify(enricher, dto.JobSucceeded)
+			} else {
+				enrichersToExecute = append(enrichersToExecute, enricher)
+			}
+		}
+
+		waveData := enricherData
+		waveData.Upstream = getUpstreamResults(upstream)
+
+		outcomes := common.ParallelExecute(ctx, waveData, enrichersToExecute, processor, executor.maxConcurrency)
+		for i, outcome := range outcomes {
+			enricher := enrichersToExecute[i]
+			result := outcome.Result
+
+			if outcome.Err != nil {
+				result = newFailedResultEnvelope(enricher, enricherData, outcome.Err)
+				notify(enricher, result.Status)
+			}
+			results = append(results, result)
+			upstream[enricher.Name] = result
+			executor.stats.record(result)
+
+			if result.Status != dto.JobSucceeded {
+				continue
+			}
+			err := executor.putEnrichmentResultToCache(enricher, enricherData, result)
+			if err != nil {
+				log.Printf("Error putting enrichment result to cache: %v", err)
 			}
 		}
 	}
 
-	return results, common.MergeErrors(errors)
+	return results, nil
+}
+
+// acquireProcess waits for a slot of the executor wide process limit, which
+// is shared by all the requests, jobs and batches.
+func (executor EnricherExecutorService) acquireProcess(ctx context.Context) (func(), error) {
+	if executor.processes == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case executor.processes <- struct{}{}:
+		return func() { <-executor.processes }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newFailedResultEnvelope(enricher dto.Enricher, enricherData dto.EnricherInputData, err error) dto.EnricherResultEnvelope {
+	now := time.Now()
+	return dto.EnricherResultEnvelope{
+		Enricher:   enricher.Name,
+		Version:    enricher.Version,
+		Source:     enricher.Source,
+		Observable: enricherData.Data,
+		DataType:   enricherData.DataType,
+		File:       enricherData.File,
+		StartedAt:  now,
+		FinishedAt: now,
+		ExitCode:   -1,
+		Status:     dto.JobFailed,
+		Error:      err.Error(),
+	}
+}
+
+// GetExecutionStatus fails an execution without results, nothing ran when
+// all the enrichers were filtered out or excluded.
+func GetExecutionStatus(results []dto.EnricherResultEnvelope) dto.JobStatus {
+	if len(results) == 0 {
+		return dto.JobFailed
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Status == dto.JobSucceeded {
+			succeeded++
+		}
+	}
+
+	switch {
+	case succeeded == len(results):
+		return dto.JobSucceeded
+	case succeeded == 0:
+		return dto.JobFailed
+	default:
+		return dto.JobPartiallySucceeded
+	}
 }
 
 func getEnabledEnrichers(enricher []dto.Enricher) []dto.Enricher {
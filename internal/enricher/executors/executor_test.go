@@ -0,0 +1,147 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"strings"
+	"testing"
+)
+
+const md5Hash = "d41d8cd98f00b204e9800998ecf8427e"
+
+func TestGetEnrichmentResultCacheKey(t *testing.T) {
+	executor := EnricherExecutorService{}
+	enricher := dto.Enricher{
+		Name:       "whois",
+		ConfigArgs: []dto.EnricherConfigArg{{Name: "maxResults", Type: dto.IntArg}},
+		Args:       map[string]any{"maxResults": 10},
+	}
+	file := &dto.FileAttributes{Name: "sample.bin", SHA256: strings.Repeat("a", 64)}
+
+	tests := []struct {
+		name      string
+		first     dto.EnricherInputData
+		second    dto.EnricherInputData
+		wantEqual bool
+	}{
+		{
+			name:      "same request",
+			first:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			second:    dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			wantEqual: true,
+		},
+		{
+			name:   "other observable",
+			first:  dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			second: dto.EnricherInputData{Data: "example.org", DataType: dto.DOMAIN},
+		},
+		{
+			name:   "other data type",
+			first:  dto.EnricherInputData{Data: md5Hash, DataType: dto.HASH},
+			second: dto.EnricherInputData{Data: md5Hash, DataType: dto.MD5},
+		},
+		{
+			name:      "same file at another path",
+			first:     dto.EnricherInputData{Data: "/tmp/upload-1", DataType: dto.FILE, File: file},
+			second:    dto.EnricherInputData{Data: "/tmp/upload-2", DataType: dto.FILE, File: file},
+			wantEqual: true,
+		},
+		{
+			name:   "request args",
+			first:  dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			second: dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, Args: map[string]map[string]any{"whois": {"maxResults": 5}}},
+		},
+		{
+			name:      "request args equal to the config args",
+			first:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			second:    dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, Args: map[string]map[string]any{"WHOIS": {"maxresults": "10"}}},
+			wantEqual: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, err := executor.getEnrichmentResultCacheKey(enricher, tt.first)
+			if err != nil {
+				t.Fatalf("getEnrichmentResultCacheKey() error = %v", err)
+			}
+			second, err := executor.getEnrichmentResultCacheKey(enricher, tt.second)
+			if err != nil {
+				t.Fatalf("getEnrichmentResultCacheKey() error = %v", err)
+			}
+			if (first == second) != tt.wantEqual {
+				t.Errorf("cache keys %s and %s, want equal %v", first, second, tt.wantEqual)
+			}
+		})
+	}
+
+	if _, err := executor.getEnrichmentResultCacheKey(enricher, dto.EnricherInputData{Args: map[string]map[string]any{"whois": {"unknown": 1}}}); err == nil {
+		t.Error("getEnrichmentResultCacheKey() with an unknown arg, want error")
+	}
+}
+
+func TestGetEnrichmentResultCacheTTL(t *testing.T) {
+	executor := EnricherExecutorService{defaultCacheTTL: 60}
+
+	tests := []struct {
+		name     string
+		enricher dto.Enricher
+		data     dto.EnricherInputData
+		want     int
+	}{
+		{
+			name: "service default",
+			data: dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want: 60,
+		},
+		{
+			name:     "enricher ttl",
+			enricher: dto.Enricher{CacheTTL: 120},
+			data:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want:     120,
+		},
+		{
+			name:     "data type ttl",
+			enricher: dto.Enricher{CacheTTL: 120, CacheTTLByType: map[dto.EnricherArgType]int64{dto.DOMAIN: 30}},
+			data:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want:     30,
+		},
+		{
+			name:     "ttl of another data type",
+			enricher: dto.Enricher{CacheTTL: 120, CacheTTLByType: map[dto.EnricherArgType]int64{dto.IP: 30}},
+			data:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want:     120,
+		},
+		{
+			name:     "resolved type before the requested type",
+			enricher: dto.Enricher{CacheTTLByType: map[dto.EnricherArgType]int64{dto.MD5: 10, dto.HASH: 20}},
+			data:     dto.EnricherInputData{Data: md5Hash, DataType: dto.HASH},
+			want:     10,
+		},
+		{
+			name:     "generic type of the resolved type",
+			enricher: dto.Enricher{CacheTTLByType: map[dto.EnricherArgType]int64{dto.HASH: 20}},
+			data:     dto.EnricherInputData{Data: md5Hash, DataType: dto.MD5},
+			want:     20,
+		},
+		{
+			name:     "zero data type ttl is ignored",
+			enricher: dto.Enricher{CacheTTL: 120, CacheTTLByType: map[dto.EnricherArgType]int64{dto.DOMAIN: 0}},
+			data:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want:     120,
+		},
+		{
+			name:     "cache disabled",
+			enricher: dto.Enricher{DisableCache: true, CacheTTL: 120, CacheTTLByType: map[dto.EnricherArgType]int64{dto.DOMAIN: 30}},
+			data:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executor.getEnrichmentResultCacheTTL(tt.enricher, tt.data); got != tt.want {
+				t.Errorf("getEnrichmentResultCacheTTL() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
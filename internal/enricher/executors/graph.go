@@ -0,0 +1,151 @@
+package executors
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"log"
+)
+
+// ExecuteGraph enriches the observable and then, breadth first up to the
+// pivot depth, the observables discovered by the enrichers. Every observable
+// is enriched once, so cycles end at the already known node. The fan-out
+// limits the observables taken from a single result and MaxObservables the
+// nodes of the whole graph. The graph is nil when pivoting is disabled.
+func (executor EnricherExecutorService) ExecuteGraph(ctx context.Context, enricherData dto.EnricherInputData, progress ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error) {
+	results, err := executor.ExecuteEnrichers(ctx, enricherData, progress)
+
+	pivot := executor.config.Pivot
+	if err != nil || pivot.MaxDepth <= 0 {
+		return results, nil, err
+	}
+
+	root := dto.Observable{Value: enricherData.Data, Type: enricherData.DataType}
+	graph := newGraphBuilder(root, pivot)
+	wave := graph.discover(root, 0, results)
+
+	for depth := 1; depth <= pivot.MaxDepth && len(wave) > 0; depth++ {
+		var nextWave []dto.Observable
+
+		for _, observable := range wave {
+			if ctx.Err() != nil {
+				return results, graph.build(), nil
+			}
+
+			pivotResults, err := executor.ExecuteEnrichers(ctx, getPivotInputData(enricherData, observable), progress)
+			if err != nil {
+				log.Printf("Pivot to %s skipped: %v", observable.ID(), err)
+				continue
+			}
+			graph.markEnriched(observable)
+			results = append(results, pivotResults...)
+
+			nextWave = append(nextWave, graph.discover(observable, depth, pivotResults)...)
+		}
+		wave = nextWave
+	}
+
+	return results, graph.build(), nil
+}
+
+// getPivotInputData keeps the profile, tags and args of the request. The
+// explicit enrichers selection applies to the requested observable only.
+func getPivotInputData(enricherData dto.EnricherInputData, observable dto.Observable) dto.EnricherInputData {
+	enricherData.Data = observable.Value
+	enricherData.DataType = observable.Type
+	enricherData.Enrichers = nil
+	enricherData.Exclude = nil
+	enricherData.File = nil
+
+	return enricherData
+}
+
+type graphBuilder struct {
+	pivot configs.PivotConfig
+	nodes map[string]int
+	edges map[dto.ObservableEdge]bool
+	graph dto.EnrichmentGraph
+}
+
+func newGraphBuilder(root dto.Observable, pivot configs.PivotConfig) *graphBuilder {
+	builder := &graphBuilder{
+		pivot: pivot,
+		nodes: make(map[string]int),
+		edges: make(map[dto.ObservableEdge]bool),
+	}
+	builder.addNode(root, 0)
+	builder.markEnriched(root)
+
+	return builder
+}
+
+func (builder *graphBuilder) addNode(observable dto.Observable, depth int) {
+	builder.nodes[observable.ID()] = len(builder.graph.Nodes)
+	builder.graph.Nodes = append(builder.graph.Nodes, dto.ObservableNode{
+		ID:    observable.ID(),
+		Value: observable.Value,
+		Type:  observable.Type,
+		Depth: depth,
+	})
+}
+
+func (builder *graphBuilder) markEnriched(observable dto.Observable) {
+	if index, exists := builder.nodes[observable.ID()]; exists {
+		builder.graph.Nodes[index].Enriched = true
+	}
+}
+
+// discover links the observables found in the results to the source node
+// and returns the new ones which are still within the pivot depth.
+func (builder *graphBuilder) discover(source dto.Observable, depth int, results []dto.EnricherResultEnvelope) []dto.Observable {
+	var discovered []dto.Observable
+
+	for _, result := range results {
+		if result.Status != dto.JobSucceeded {
+			continue
+		}
+
+		resultObservables := result.Result.Observables
+		if builder.pivot.MaxFanOut > 0 && len(resultObservables) > builder.pivot.MaxFanOut {
+			log.Printf("Enricher %s discovered %d observables, only %d are pivoted", result.Enricher, len(resultObservables), builder.pivot.MaxFanOut)
+			resultObservables = resultObservables[:builder.pivot.MaxFanOut]
+		}
+
+		for _, observable := range resultObservables {
+			value, err := observables.Normalize(observable.Type, observable.Value)
+			if err != nil {
+				log.Printf("Enricher %s discovered %v", result.Enricher, err)
+				continue
+			}
+			observable.Value = value
+			if observable.Type == dto.FILE {
+				log.Printf("Enricher %s discovered a FILE observable, files are not pivoted", result.Enricher)
+				continue
+			}
+
+			if _, exists := builder.nodes[observable.ID()]; !exists {
+				if builder.pivot.MaxObservables > 0 && len(builder.graph.Nodes) >= builder.pivot.MaxObservables {
+					continue
+				}
+				builder.addNode(observable, depth+1)
+				if depth+1 <= builder.pivot.MaxDepth {
+					discovered = append(discovered, observable)
+				}
+			}
+
+			edge := dto.ObservableEdge{From: source.ID(), To: observable.ID(), Enricher: result.Enricher}
+			if !builder.edges[edge] {
+				builder.edges[edge] = true
+				builder.graph.Edges = append(builder.graph.Edges, edge)
+			}
+		}
+	}
+
+	return discovered
+}
+
+func (builder *graphBuilder) build() *dto.EnrichmentGraph {
+	graph := builder.graph
+	return &graph
+}
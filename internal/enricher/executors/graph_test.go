@@ -0,0 +1,146 @@
+package executors
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// pivotProcessor discovers the observables of a fixed graph of domains and
+// counts the enrichments of every observable.
+type pivotProcessor struct {
+	pivots   map[string][]string
+	mu       sync.Mutex
+	executed map[string]int
+}
+
+func (processor *pivotProcessor) process(ctx context.Context, enricher dto.Enricher, data dto.EnricherInputData) (dto.EnricherResultEnvelope, error) {
+	processor.mu.Lock()
+	processor.executed[data.Data]++
+	processor.mu.Unlock()
+
+	var discovered []dto.Observable
+	for _, value := range processor.pivots[data.Data] {
+		discovered = append(discovered, dto.Observable{Value: value, Type: dto.DOMAIN})
+	}
+	return dto.EnricherResultEnvelope{Observable: data.Data, DataType: data.DataType, Result: dto.EnricherResult{Observables: discovered}}, nil
+}
+
+func TestExecuteGraph(t *testing.T) {
+	// b.com links back to a.com
+	pivots := map[string][]string{
+		"a.com": {"b.com", "c.com", "d.com"},
+		"b.com": {"a.com", "e.com"},
+		"e.com": {"f.com"},
+	}
+
+	tests := []struct {
+		name         string
+		pivot        configs.PivotConfig
+		wantGraph    bool
+		wantEnriched []string
+		wantNodes    []string
+		wantEdges    int
+	}{
+		{
+			name:         "pivoting disabled",
+			wantEnriched: []string{"a.com"},
+		},
+		{
+			name:         "max depth",
+			pivot:        configs.PivotConfig{MaxDepth: 1},
+			wantGraph:    true,
+			wantEnriched: []string{"a.com", "b.com", "c.com", "d.com"},
+			wantNodes:    []string{"a.com", "b.com", "c.com", "d.com", "e.com"},
+			wantEdges:    5,
+		},
+		{
+			name:         "cycles are enriched once",
+			pivot:        configs.PivotConfig{MaxDepth: 5},
+			wantGraph:    true,
+			wantEnriched: []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com"},
+			wantNodes:    []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com"},
+			wantEdges:    6,
+		},
+		{
+			name:         "max fan-out",
+			pivot:        configs.PivotConfig{MaxDepth: 5, MaxFanOut: 1},
+			wantGraph:    true,
+			wantEnriched: []string{"a.com", "b.com"},
+			wantNodes:    []string{"a.com", "b.com"},
+			wantEdges:    2,
+		},
+		{
+			name:         "max observables",
+			pivot:        configs.PivotConfig{MaxDepth: 5, MaxObservables: 3},
+			wantGraph:    true,
+			wantEnriched: []string{"a.com", "b.com", "c.com"},
+			wantNodes:    []string{"a.com", "b.com", "c.com"},
+			wantEdges:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &pivotProcessor{pivots: pivots, executed: make(map[string]int)}
+			executor := EnricherExecutorService{
+				enrichers: staticEnrichers{
+					dto.DOMAIN: {{Name: "resolver", Enabled: true, DisableCache: true, AllowedTypes: []dto.EnricherArgType{dto.DOMAIN}}},
+				},
+				config:    configs.ExecutorConfig{Pivot: tt.pivot},
+				stats:     newEnricherStats(),
+				processor: processor.process,
+			}
+
+			results, graph, err := executor.ExecuteGraph(context.Background(), dto.EnricherInputData{Data: "a.com", DataType: dto.DOMAIN}, nil)
+			if err != nil {
+				t.Fatalf("ExecuteGraph() error = %v", err)
+			}
+
+			var enriched []string
+			for _, result := range results {
+				enriched = append(enriched, result.Observable)
+			}
+			slices.Sort(enriched)
+			if !slices.Equal(enriched, tt.wantEnriched) {
+				t.Errorf("enriched observables = %v, want %v", enriched, tt.wantEnriched)
+			}
+			for value, count := range processor.executed {
+				if count != 1 {
+					t.Errorf("observable %s enriched %d times", value, count)
+				}
+			}
+
+			if (graph != nil) != tt.wantGraph {
+				t.Fatalf("ExecuteGraph() graph = %v, want graph %v", graph, tt.wantGraph)
+			}
+			if graph == nil {
+				return
+			}
+			var nodes []string
+			ids := make(map[string]bool)
+			for _, node := range graph.Nodes {
+				nodes = append(nodes, node.Value)
+				ids[node.ID] = true
+				if node.Enriched != slices.Contains(tt.wantEnriched, node.Value) {
+					t.Errorf("node %s enriched = %v", node.ID, node.Enriched)
+				}
+			}
+			slices.Sort(nodes)
+			if !slices.Equal(nodes, tt.wantNodes) {
+				t.Errorf("graph nodes = %v, want %v", nodes, tt.wantNodes)
+			}
+			if len(graph.Edges) != tt.wantEdges {
+				t.Errorf("graph edges = %v, want %d", graph.Edges, tt.wantEdges)
+			}
+			for _, edge := range graph.Edges {
+				if !ids[edge.From] || !ids[edge.To] {
+					t.Errorf("graph edge %v links a missing node", edge)
+				}
+			}
+		})
+	}
+}
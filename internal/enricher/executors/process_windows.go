@@ -0,0 +1,13 @@
+//go:build windows
+
+package executors
+
+import (
+	"os/exec"
+)
+
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,57 @@
+package executors
+
+import (
+	"enricher/configs"
+	"enricher/internal/enricher/args"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+func (executor EnricherExecutorService) getProfile(name string) (configs.ProfileConfig, error) {
+	if name == "" {
+		return configs.ProfileConfig{}, nil
+	}
+
+	for profileName, profile := range executor.profiles {
+		if strings.EqualFold(profileName, name) {
+			return profile, nil
+		}
+	}
+	return configs.ProfileConfig{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+}
+
+// applyProfile sets the profile timeouts on the selected enrichers and
+// returns input data with the profile args, overridden by the request args.
+// Args are keyed by their declared name, so a request arg overrides the
+// profile one however either of them is spelled.
+func applyProfile(profile configs.ProfileConfig, enrichers []dto.Enricher, enricherData dto.EnricherInputData) ([]dto.Enricher, dto.EnricherInputData) {
+	profiledEnrichers := make([]dto.Enricher, 0, len(enrichers))
+	profiledArgs := make(map[string]map[string]any, len(enrichers))
+
+	for _, enricher := range enrichers {
+		for name, timeout := range profile.Timeouts {
+			if strings.EqualFold(name, enricher.Name) && timeout > 0 {
+				enricher.Timeout = timeout
+			}
+		}
+		profiledEnrichers = append(profiledEnrichers, enricher)
+
+		values := make(map[string]any)
+		for name, value := range args.Configured(profile.Args, enricher.Name) {
+			values[args.Name(enricher, name)] = value
+		}
+		for name, value := range args.Configured(enricherData.Args, enricher.Name) {
+			values[args.Name(enricher, name)] = value
+		}
+		if len(values) > 0 {
+			profiledArgs[enricher.Name] = values
+		}
+	}
+	enricherData.Args = profiledArgs
+
+	return profiledEnrichers, enricherData
+}
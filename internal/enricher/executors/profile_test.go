@@ -0,0 +1,81 @@
+package executors
+
+import (
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"reflect"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	enricher := dto.Enricher{
+		Name:    "whois",
+		Timeout: 10,
+		ConfigArgs: []dto.EnricherConfigArg{
+			{Name: "apiKey", Type: dto.StringArg},
+			{Name: "maxResults", Type: dto.IntArg},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		profile     configs.ProfileConfig
+		requestArgs map[string]map[string]any
+		wantArgs    map[string]map[string]any
+		wantTimeout int64
+	}{
+		{
+			name:        "no profile",
+			wantArgs:    map[string]map[string]any{},
+			wantTimeout: 10,
+		},
+		{
+			name: "profile args and timeout",
+			profile: configs.ProfileConfig{
+				Args:     map[string]map[string]any{"whois": {"apikey": "profile"}},
+				Timeouts: map[string]int64{"WHOIS": 30},
+			},
+			wantArgs:    map[string]map[string]any{"whois": {"apiKey": "profile"}},
+			wantTimeout: 30,
+		},
+		{
+			name: "request overrides the profile spelled differently",
+			profile: configs.ProfileConfig{
+				Args: map[string]map[string]any{"whois": {"apikey": "profile", "maxresults": 5}},
+			},
+			requestArgs: map[string]map[string]any{"Whois": {"apiKey": "request"}},
+			wantArgs:    map[string]map[string]any{"whois": {"apiKey": "request", "maxResults": 5}},
+			wantTimeout: 10,
+		},
+		{
+			name:        "undeclared args are kept for validation",
+			requestArgs: map[string]map[string]any{"whois": {"unknown": true}},
+			wantArgs:    map[string]map[string]any{"whois": {"unknown": true}},
+			wantTimeout: 10,
+		},
+		{
+			name: "args of other enrichers are dropped",
+			profile: configs.ProfileConfig{
+				Args:     map[string]map[string]any{"dns": {"apikey": "profile"}},
+				Timeouts: map[string]int64{"dns": 30},
+			},
+			wantArgs:    map[string]map[string]any{},
+			wantTimeout: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				enrichers, data := applyProfile(tt.profile, []dto.Enricher{enricher}, dto.EnricherInputData{Args: tt.requestArgs})
+
+				if !reflect.DeepEqual(data.Args, tt.wantArgs) {
+					t.Fatalf("applyProfile() args = %v, want %v", data.Args, tt.wantArgs)
+				}
+				if enrichers[0].Timeout != tt.wantTimeout {
+					t.Fatalf("applyProfile() timeout = %d, want %d", enrichers[0].Timeout, tt.wantTimeout)
+				}
+			}
+		})
+	}
+}
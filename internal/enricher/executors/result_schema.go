@@ -0,0 +1,48 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"fmt"
+	"log"
+	"strings"
+)
+
+func (executor EnricherExecutorService) getSchemaPolicy(enricher dto.Enricher) dto.SchemaPolicy {
+	if enricher.SchemaPolicy != "" {
+		return enricher.SchemaPolicy
+	}
+
+	if executor.config.SchemaPolicy != "" {
+		return dto.SchemaPolicy(executor.config.SchemaPolicy)
+	}
+	return dto.WarnSchemaPolicy
+}
+
+// validateResult checks the report against the enricher result schema.
+// Violations are added to the result errors, with the reject policy they
+// also fail the result. The pass policy skips the validation.
+func (executor EnricherExecutorService) validateResult(enricher dto.Enricher, result *dto.EnricherResultEnvelope) error {
+	policy := executor.getSchemaPolicy(enricher)
+	if enricher.CompiledSchema == nil || policy == dto.PassSchemaPolicy {
+		return nil
+	}
+
+	violations, err := enricher.CompiledSchema.Validate(result.Result.Report)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	for _, violation := range violations {
+		result.Result.Errors = append(result.Result.Errors, fmt.Sprintf("schema: %s", violation))
+	}
+
+	if policy == dto.RejectSchemaPolicy {
+		return fmt.Errorf("result of enricher %s does not match schema", enricher.Name)
+	}
+	log.Printf("Result of enricher %s does not match schema: %s", enricher.Name, strings.Join(violations, "; "))
+
+	return nil
+}
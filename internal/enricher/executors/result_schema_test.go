@@ -0,0 +1,107 @@
+package executors
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/schema"
+	"strings"
+	"testing"
+)
+
+func TestSchemaPolicies(t *testing.T) {
+	resultSchema, err := schema.Compile([]byte(`{"type": "object", "required": ["score"], "properties": {"score": {"type": "number"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validReport := map[string]any{"score": 10}
+	invalidReport := map[string]any{"score": "high"}
+
+	tests := []struct {
+		name           string
+		configPolicy   string
+		enricherPolicy dto.SchemaPolicy
+		report         map[string]any
+		wantStatus     dto.JobStatus
+		wantErrors     bool
+	}{
+		{
+			name:       "valid report",
+			report:     validReport,
+			wantStatus: dto.JobSucceeded,
+		},
+		{
+			name:       "warn by default",
+			report:     invalidReport,
+			wantStatus: dto.JobSucceeded,
+			wantErrors: true,
+		},
+		{
+			name:         "reject",
+			configPolicy: "reject",
+			report:       invalidReport,
+			wantStatus:   dto.JobFailed,
+			wantErrors:   true,
+		},
+		{
+			name:         "pass",
+			configPolicy: "pass",
+			report:       invalidReport,
+			wantStatus:   dto.JobSucceeded,
+		},
+		{
+			name:           "enricher policy before the config policy",
+			configPolicy:   "reject",
+			enricherPolicy: dto.WarnSchemaPolicy,
+			report:         invalidReport,
+			wantStatus:     dto.JobSucceeded,
+			wantErrors:     true,
+		},
+		{
+			name:           "enricher reject policy",
+			configPolicy:   "pass",
+			enricherPolicy: dto.RejectSchemaPolicy,
+			report:         invalidReport,
+			wantStatus:     dto.JobFailed,
+			wantErrors:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher := dto.Enricher{
+				Name:           "reputation",
+				Enabled:        true,
+				DisableCache:   true,
+				AllowedTypes:   []dto.EnricherArgType{dto.DOMAIN},
+				SchemaPolicy:   tt.enricherPolicy,
+				CompiledSchema: resultSchema,
+			}
+			executor := EnricherExecutorService{
+				enrichers: staticEnrichers{dto.DOMAIN: {enricher}},
+				config:    configs.ExecutorConfig{SchemaPolicy: tt.configPolicy},
+				stats:     newEnricherStats(),
+				processor: func(ctx context.Context, enricher dto.Enricher, data dto.EnricherInputData) (dto.EnricherResultEnvelope, error) {
+					return dto.EnricherResultEnvelope{Result: dto.EnricherResult{Report: tt.report}}, nil
+				},
+			}
+
+			results, err := executor.ExecuteEnrichers(context.Background(), dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN}, nil)
+			if err != nil {
+				t.Fatalf("ExecuteEnrichers() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("ExecuteEnrichers() = %v, want one result", results)
+			}
+
+			result := results[0]
+			if result.Status != tt.wantStatus {
+				t.Errorf("result status = %s, want %s", result.Status, tt.wantStatus)
+			}
+			hasSchemaErrors := len(result.Result.Errors) > 0 && strings.HasPrefix(result.Result.Errors[0], "schema: ")
+			if hasSchemaErrors != tt.wantErrors {
+				t.Errorf("result errors = %v, want schema errors %v", result.Result.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package executors
+
+import (
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type UnknownEnrichersError struct {
+	Unknown []string
+	Valid   []string
+}
+
+func (err *UnknownEnrichersError) Error() string {
+	return fmt.Sprintf(
+		"unknown enrichers: %s, valid choices: %s",
+		strings.Join(err.Unknown, ", "),
+		strings.Join(err.Valid, ", "),
+	)
+}
+
+// UnavailableEnrichersError lists the requested enrichers which exist but
+// can not enrich the data type, either because they do not support it or
+// because they are disabled.
+type UnavailableEnrichersError struct {
+	DataType      dto.EnricherArgType
+	NotApplicable []string
+	Disabled      []string
+	Valid         []string
+}
+
+func (err *UnavailableEnrichersError) Error() string {
+	var reasons []string
+	if len(err.NotApplicable) > 0 {
+		reasons = append(reasons, fmt.Sprintf("enrichers not applicable to %s: %s", err.DataType, strings.Join(err.NotApplicable, ", ")))
+	}
+	if len(err.Disabled) > 0 {
+		reasons = append(reasons, fmt.Sprintf("disabled enrichers: %s", strings.Join(err.Disabled, ", ")))
+	}
+	return fmt.Sprintf("%s, valid choices: %s", strings.Join(reasons, ", "), strings.Join(err.Valid, ", "))
+}
+
+func (executor EnricherExecutorService) SelectEnrichers(enricherData dto.EnricherInputData) ([]dto.Enricher, error) {
+	profile, err := executor.getProfile(enricherData.Profile)
+	if err != nil {
+		return nil, err
+	}
+	return executor.selectEnrichers(enricherData, profile)
+}
+
+// selectEnrichers filters the enabled enrichers for the data type. Request
+// enrichers and tags take precedence over the profile ones, exclusions of
+// both are applied. Only names given in the request are validated, so a
+// profile may list enrichers of other data types. Dependencies of the
+// selected enrichers are added unless excluded.
+func (executor EnricherExecutorService) selectEnrichers(enricherData dto.EnricherInputData, profile configs.ProfileConfig) ([]dto.Enricher, error) {
+	catalog := executor.enrichers.Enrichers()
+	dataType := getSelectionType(enricherData)
+	allowedEnrichersList, exists := catalog[dataType]
+	if !exists {
+		errorMessage := fmt.Sprintf("Enricher for data type %s not found", string(dataType))
+		return nil, errors.New(errorMessage)
+	}
+
+	enabledEnrichers := getEnabledEnrichers(allowedEnrichersList)
+
+	if err := validateEnricherNames(catalog, dataType, enricherData.Enrichers, enricherData.Exclude); err != nil {
+		return nil, err
+	}
+
+	include := enricherData.Enrichers
+	if len(include) == 0 {
+		include = profile.Enrichers
+	}
+	tags := enricherData.Tags
+	if len(tags) == 0 {
+		tags = profile.Tags
+	}
+
+	var selectedEnrichers []dto.Enricher
+	for _, enricher := range enabledEnrichers {
+		if len(include) > 0 && !slices.Contains(include, enricher.Name) {
+			continue
+		}
+		if slices.Contains(enricherData.Exclude, enricher.Name) || slices.Contains(profile.Exclude, enricher.Name) {
+			continue
+		}
+		if len(tags) > 0 && !hasAnyTag(enricher, tags) {
+			continue
+		}
+		selectedEnrichers = append(selectedEnrichers, enricher)
+	}
+
+	return withDependencies(selectedEnrichers, enabledEnrichers, enricherData.Exclude, profile.Exclude), nil
+}
+
+// getSelectionType refines IP and HASH observables to their subtype. The
+// enrichers and cache TTLs of the subtype apply, while the enrichers still
+// get the requested type.
+func getSelectionType(enricherData dto.EnricherInputData) dto.EnricherArgType {
+	dataType, _, err := observables.Resolve(enricherData.DataType, enricherData.Data)
+	if err != nil {
+		return enricherData.DataType
+	}
+	return dataType
+}
+
+// validateEnricherNames checks the names against the whole catalog. Names
+// of no enricher are unknown, the enrichers which do not support the data
+// type or are disabled are unavailable.
+func validateEnricherNames(catalog map[dto.EnricherArgType][]dto.Enricher, dataType dto.EnricherArgType, names ...[]string) error {
+	known := make(map[string]bool)
+	for _, enrichers := range catalog {
+		for _, enricher := range enrichers {
+			known[enricher.Name] = true
+		}
+	}
+
+	applicable := make(map[string]bool)
+	var valid []string
+	for _, enricher := range catalog[dataType] {
+		applicable[enricher.Name] = true
+		if enricher.Enabled {
+			valid = append(valid, enricher.Name)
+		}
+	}
+	sort.Strings(valid)
+
+	var unknown, notApplicable, disabled []string
+	for _, list := range names {
+		for _, name := range list {
+			switch {
+			case slices.Contains(valid, name):
+			case !known[name]:
+				unknown = appendMissing(unknown, name)
+			case !applicable[name]:
+				notApplicable = appendMissing(notApplicable, name)
+			default:
+				disabled = appendMissing(disabled, name)
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		return &UnknownEnrichersError{Unknown: unknown, Valid: valid}
+	}
+	if len(notApplicable) > 0 || len(disabled) > 0 {
+		return &UnavailableEnrichersError{DataType: dataType, NotApplicable: notApplicable, Disabled: disabled, Valid: valid}
+	}
+	return nil
+}
+
+func appendMissing(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func hasAnyTag(enricher dto.Enricher, tags []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(enricher.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
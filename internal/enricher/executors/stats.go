@@ -0,0 +1,46 @@
+package executors
+
+import (
+	"enricher/internal/enricher/dto"
+	"sync"
+	"time"
+)
+
+type enricherStats struct {
+	stats map[string]dto.EnricherStats
+	mu    sync.RWMutex
+}
+
+func newEnricherStats() *enricherStats {
+	return &enricherStats{
+		stats: make(map[string]dto.EnricherStats),
+	}
+}
+
+func (stats *enricherStats) record(result dto.EnricherResultEnvelope) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	enricherStats := stats.stats[result.Enricher]
+	finishedAt := result.FinishedAt
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+
+	enricherStats.Runs++
+	if result.Status != dto.JobSucceeded {
+		enricherStats.Failures++
+	}
+	enricherStats.FailureRate = float64(enricherStats.Failures) / float64(enricherStats.Runs)
+	enricherStats.LastRunAt = &finishedAt
+	enricherStats.LastStatus = result.Status
+
+	stats.stats[result.Enricher] = enricherStats
+}
+
+func (stats *enricherStats) get(enricherName string) dto.EnricherStats {
+	stats.mu.RLock()
+	defer stats.mu.RUnlock()
+
+	return stats.stats[enricherName]
+}
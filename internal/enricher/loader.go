@@ -2,89 +2,179 @@ package enricher
 
 import (
 	"encoding/json"
+	"enricher/configs"
+	"enricher/internal/enricher/args"
 	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"enricher/internal/enricher/schema"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 )
 
-func loadEnrichers(enrichersPath string) (map[dto.EnricherArgType][]dto.Enricher, error) {
-	var enrichers = make(map[dto.EnricherArgType][]dto.Enricher)
+var (
+	ErrEnricherNotFound = errors.New("enricher not found")
+	ErrInvalidOverride  = errors.New("invalid enricher override")
 
+	errSettingsNotFound = errors.New("settings.json not found")
+	errNotDirectory     = errors.New("not a directory")
+)
+
+type loadedEnricher struct {
+	directory string
+	enricher  dto.Enricher
+	err       error
+}
+
+func loadEnricher(pluginPath string, argsConfig map[string]map[string]any, registry *observables.Registry) (dto.Enricher, error) {
+	settingsPath := filepath.Join(pluginPath, "settings.json")
+
+	info, err := os.Stat(settingsPath)
+	if err != nil || info.IsDir() {
+		return dto.Enricher{}, errSettingsNotFound
+	}
+	file, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return dto.Enricher{}, fmt.Errorf("read enricher config file error: %w", err)
+	}
+
+	var enricher dto.Enricher
+	err = json.Unmarshal(file, &enricher)
+
+	if err != nil {
+		return dto.Enricher{}, fmt.Errorf("unmarshal enricher error: %w", err)
+	}
+
+	executablePath := filepath.Join(pluginPath, enricher.ExecutablePath)
+
+	absoluteExecutablePath, err := filepath.Abs(executablePath)
+	if err != nil {
+		return enricher, fmt.Errorf("error converting to absolute path: %w", err)
+	}
+	enricher.ExecutablePath = absoluteExecutablePath
+
+	if enricher.ResultSchemaPath != "" {
+		if len(enricher.ResultSchema) > 0 {
+			return enricher, fmt.Errorf("enricher %s declares both resultSchema and resultSchemaPath", enricher.Name)
+		}
+		enricher.ResultSchema, err = os.ReadFile(filepath.Join(pluginPath, enricher.ResultSchemaPath))
+		if err != nil {
+			return enricher, fmt.Errorf("read enricher result schema error: %w", err)
+		}
+	}
+
+	err = validateEnricher(enricher, registry)
+
+	if err != nil {
+		return enricher, fmt.Errorf("validation enricher %s failed: %w", enricher.Name, err)
+	}
+
+	if len(enricher.ResultSchema) > 0 {
+		enricher.CompiledSchema, err = schema.Compile(enricher.ResultSchema)
+
+		if err != nil {
+			return enricher, fmt.Errorf("validation enricher %s failed: %w", enricher.Name, err)
+		}
+	}
+
+	enricher.Args, err = args.Resolve(enricher, args.Configured(argsConfig, enricher.Name))
+
+	if err != nil {
+		return enricher, fmt.Errorf("resolving enricher %s arguments failed: %w", enricher.Name, err)
+	}
+
+	return enricher, nil
+}
+
+func loadEnrichers(enrichersPath string, argsConfig map[string]map[string]any, registry *observables.Registry, typeErrors map[string]error) ([]loadedEnricher, error) {
 	entries, err := os.ReadDir(enrichersPath)
 	if err != nil {
 		log.Printf("Error reading Enrichers from path %s: %v", enrichersPath, err)
 		return nil, err
 	}
+	entries = slices.DeleteFunc(entries, func(entry os.DirEntry) bool {
+		return entry.Name() == typesManifestName
+	})
+
+	loaded := make([]loadedEnricher, len(entries))
 
 	var wg sync.WaitGroup
-	for _, entry := range entries {
+	for i, entry := range entries {
 		if !entry.IsDir() {
+			loaded[i] = loadedEnricher{directory: entry.Name(), err: errNotDirectory}
 			continue
 		}
 		wg.Add(1)
-		go func(entry os.DirEntry) {
+		go func(i int, entry os.DirEntry) {
 			defer wg.Done()
-			subDirPath := filepath.Join(enrichersPath, entry.Name())
-			settingsPath := filepath.Join(subDirPath, "settings.json")
-
-			info, err := os.Stat(settingsPath)
-			if err != nil || info.IsDir() {
-				return
-			}
-			file, err := os.ReadFile(settingsPath)
-			if err != nil {
-				log.Printf("Read enricher config file error: %v", err)
-				return
+			enricher, err := loadEnricher(filepath.Join(enrichersPath, entry.Name()), argsConfig, registry)
+			if typeErr, exists := typeErrors[entry.Name()]; exists && !errors.Is(err, errSettingsNotFound) {
+				err = fmt.Errorf("registering enricher %s types failed: %w", enricher.Name, typeErr)
 			}
-
-			var enricher dto.Enricher
-			err = json.Unmarshal(file, &enricher)
-
-			if err != nil {
-				log.Printf("Unmarshal enricher error: %v", err)
-				return
+			loaded[i] = loadedEnricher{
+				directory: entry.Name(),
+				enricher:  enricher,
+				err:       err,
 			}
+		}(i, entry)
+	}
+	wg.Wait()
 
-			executablePath := filepath.Join(subDirPath, enricher.ExecutablePath)
-
-			absoluteExecutablePath, err := filepath.Abs(executablePath)
-			if err != nil {
-				log.Printf("Error converting to absolute path: %v", err)
-				return
-			}
-			enricher.ExecutablePath = absoluteExecutablePath
+	return loaded, nil
+}
 
-			err = validateEnricher(enricher)
+func groupEnrichersByType(directories map[string]dto.Enricher) map[dto.EnricherArgType][]dto.Enricher {
+	enrichers := make(map[dto.EnricherArgType][]dto.Enricher)
 
-			if err != nil {
-				log.Printf("Validation enricher %s failed: %v", enricher.Name, err)
-				return
-			}
+	names := make([]string, 0, len(directories))
+	for directory := range directories {
+		names = append(names, directory)
+	}
+	sort.Strings(names)
 
-			for _, allowedType := range enricher.AllowedTypes {
-				enrichers[allowedType] = append(enrichers[allowedType], enricher)
+	for _, directory := range names {
+		enricher := directories[directory]
+		registered := make(map[dto.EnricherArgType]bool)
+		for _, allowedType := range enricher.AllowedTypes {
+			for _, dataType := range append([]dto.EnricherArgType{allowedType}, observables.Subtypes(allowedType)...) {
+				if !registered[dataType] {
+					registered[dataType] = true
+					enrichers[dataType] = append(enrichers[dataType], enricher)
+				}
 			}
-		}(entry)
+		}
 	}
-	wg.Wait()
 
-	if len(enrichers) == 0 {
-		log.Println("Enrichers not found")
-	}
+	return enrichers
+}
 
-	return enrichers, err
+type EnricherRegistry interface {
+	Enrichers() map[dto.EnricherArgType][]dto.Enricher
+	LoadReport() dto.EnricherLoadReport
+	UpdateEnricher(name string, update dto.EnricherOverride) error
+	ReloadEnrichers() (dto.EnricherLoadReport, error)
 }
 
 type enricherManager struct {
-	enrichers map[dto.EnricherArgType][]dto.Enricher
-	mu        sync.RWMutex
+	enrichers   map[dto.EnricherArgType][]dto.Enricher
+	directories map[string]dto.Enricher
+	overrides   map[string]dto.EnricherOverride
+	types       []dto.ObservableTypeDefinition
+	report      dto.EnricherLoadReport
+	config      configs.EnrichersConfig
+	mu          sync.RWMutex
 }
 
-func NewEnricherManager() *enricherManager {
+func NewEnricherManager(config configs.EnrichersConfig) *enricherManager {
 	return &enricherManager{
 		enrichers: nil,
+		config:    config,
 	}
 }
 
@@ -92,24 +182,224 @@ func (manager *enricherManager) LoadEnrichers(enrichersPath string) error {
 	manager.mu.Lock()
 	defer manager.mu.Unlock()
 
-	loadedEnrichers, err := loadEnrichers(enrichersPath)
+	if manager.overrides == nil {
+		overrides, err := loadOverrides(manager.config.OverridesPath)
+		if err != nil {
+			return err
+		}
+		manager.overrides = overrides
+	}
+
+	manifest, err := loadTypesManifest(enrichersPath)
+	if err != nil {
+		return err
+	}
+
+	registry, typeErrors, err := loadObservableTypes(enrichersPath, manifest)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := loadEnrichers(enrichersPath, manager.config.Args, registry, typeErrors)
 	if err != nil {
 		return err
 	}
 
-	manager.enrichers = loadedEnrichers
+	report := dto.EnricherLoadReport{LoadedAt: time.Now()}
+	directories := make(map[string]dto.Enricher)
+	loadedNames := make(map[string]string)
+
+	for _, result := range loaded {
+		entry := dto.EnricherLoadEntry{
+			Directory: result.directory,
+			Enricher:  result.enricher.Name,
+		}
+
+		if result.err == nil {
+			if directory, exists := loadedNames[result.enricher.Name]; exists {
+				result.err = fmt.Errorf("duplicate enricher name %s, already loaded from %s", result.enricher.Name, directory)
+			}
+		}
+
+		switch {
+		case errors.Is(result.err, errSettingsNotFound) || errors.Is(result.err, errNotDirectory):
+			entry.Reason = result.err.Error()
+			report.Skipped = append(report.Skipped, entry)
+		case result.err != nil:
+			entry.Reason = result.err.Error()
+			previous, exists := manager.directories[result.directory]
+			if _, duplicate := loadedNames[previous.Name]; exists && !duplicate && registerTypes(registry, previous) {
+				directories[result.directory] = previous
+				loadedNames[previous.Name] = result.directory
+				entry.KeptPrevious = true
+			}
+			report.Invalid = append(report.Invalid, entry)
+		default:
+			directories[result.directory] = result.enricher
+			loadedNames[result.enricher.Name] = result.directory
+			report.Loaded = append(report.Loaded, entry)
+		}
+	}
+
+	for {
+		invalid := validateTypes(directories, manifest)
+		for directory, err := range validateDependencies(directories) {
+			if _, exists := invalid[directory]; !exists {
+				invalid[directory] = err
+			}
+		}
+		if len(invalid) == 0 {
+			break
+		}
+		invalidateEnrichers(&report, directories, invalid)
+	}
+
+	logLoadReport(report)
+
+	if manager.config.Strict && len(report.Invalid) > 0 {
+		err := fmt.Errorf("%d invalid enrichers found in strict mode", len(report.Invalid))
+		report.Rejected = err.Error()
+		manager.report = report
+		return err
+	}
+
+	overridden := applyOverrides(directories, manager.overrides)
+	observables.SetRegistry(buildTypesRegistry(manifest, overridden))
+	manager.types = manifest
+	manager.directories = directories
+	manager.enrichers = groupEnrichersByType(overridden)
+	manager.report = report
 
 	return nil
 }
 
+func (manager *enricherManager) ReloadEnrichers() (dto.EnricherLoadReport, error) {
+	log.Println("Reloading enrichers...")
+	err := manager.LoadEnrichers(manager.config.Path)
+
+	return manager.LoadReport(), err
+}
+
+// UpdateEnricher merges the update into the enricher override, persists
+// the overrides and applies them without reloading the plugins.
+func (manager *enricherManager) UpdateEnricher(name string, update dto.EnricherOverride) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	if err := validateOverride(update); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+
+	found := false
+	for _, enricher := range manager.directories {
+		if enricher.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrEnricherNotFound
+	}
+
+	overrides := make(map[string]dto.EnricherOverride, len(manager.overrides)+1)
+	for enricherName, override := range manager.overrides {
+		overrides[enricherName] = override
+	}
+	overrides[name] = mergeOverride(overrides[name], update)
+
+	if err := saveOverrides(manager.config.OverridesPath, overrides); err != nil {
+		return err
+	}
+
+	overridden := applyOverrides(manager.directories, overrides)
+	observables.SetRegistry(buildTypesRegistry(manager.types, overridden))
+	manager.overrides = overrides
+	manager.enrichers = groupEnrichersByType(overridden)
+	log.Printf("Enricher %s overrides updated", name)
+
+	return nil
+}
+
+// invalidateEnrichers moves the loaded enrichers of the invalid directories
+// to the invalid ones of the report, previous versions are not kept.
+func invalidateEnrichers(report *dto.EnricherLoadReport, directories map[string]dto.Enricher, invalid map[string]error) {
+	for directory := range invalid {
+		delete(directories, directory)
+	}
+	for i, entry := range report.Invalid {
+		if _, exists := invalid[entry.Directory]; exists {
+			report.Invalid[i].KeptPrevious = false
+		}
+	}
+
+	loadedEntries := report.Loaded
+	report.Loaded = nil
+	for _, entry := range loadedEntries {
+		if err, exists := invalid[entry.Directory]; exists {
+			entry.Reason = err.Error()
+			report.Invalid = append(report.Invalid, entry)
+		} else {
+			report.Loaded = append(report.Loaded, entry)
+		}
+	}
+}
+
+// registerTypes registers the types of a previous enricher version, which
+// is kept only when they do not conflict with the newly loaded ones.
+func registerTypes(registry *observables.Registry, enricher dto.Enricher) bool {
+	for _, definition := range enricher.Types {
+		if err := registry.Register(definition); err != nil {
+			log.Printf("Enricher %s previous version can not be kept: %v", enricher.Name, err)
+			return false
+		}
+	}
+	return true
+}
+
+func (manager *enricherManager) LoadReport() dto.EnricherLoadReport {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	return manager.report
+}
+
+func logLoadReport(report dto.EnricherLoadReport) {
+	log.Printf(
+		"Enrichers load report: %d loaded, %d skipped, %d invalid",
+		len(report.Loaded),
+		len(report.Skipped),
+		len(report.Invalid),
+	)
+	for _, entry := range report.Loaded {
+		log.Printf("Enricher %s loaded from %s", entry.Enricher, entry.Directory)
+	}
+	for _, entry := range report.Invalid {
+		if entry.KeptPrevious {
+			log.Printf("Enricher from %s is invalid, keeping previous version: %s", entry.Directory, entry.Reason)
+		} else {
+			log.Printf("Enricher from %s is invalid: %s", entry.Directory, entry.Reason)
+		}
+	}
+	if len(report.Loaded) == 0 {
+		log.Println("Enrichers not found")
+	}
+}
+
+func (manager *enricherManager) Enrichers() map[dto.EnricherArgType][]dto.Enricher {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	return manager.enrichers
+}
+
 func (manager *enricherManager) GetEnrichers(enrichersPath string) (map[dto.EnricherArgType][]dto.Enricher, error) {
 
-	if manager.enrichers == nil || len(manager.enrichers) == 0 {
+	if len(manager.Enrichers()) == 0 {
 		err := manager.LoadEnrichers(enrichersPath)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return manager.enrichers, nil
+	return manager.Enrichers(), nil
 }
@@ -0,0 +1,157 @@
+package enricher
+
+import (
+	"encoding/json"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeEnricher writes the settings.json of an enricher directory with an
+// executable, a missing executable makes the enricher invalid.
+func writeEnricher(t *testing.T, enrichersPath string, directory string, name string, executable bool) {
+	t.Helper()
+
+	path := filepath.Join(enrichersPath, directory)
+	if err := os.MkdirAll(path, 0700); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := json.Marshal(dto.Enricher{
+		Enabled:        true,
+		Name:           name,
+		ExecutablePath: "run.sh",
+		AllowedTypes:   []dto.EnricherArgType{dto.DOMAIN},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "settings.json"), settings, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if executable {
+		if err := os.WriteFile(filepath.Join(path, "run.sh"), []byte("#!/bin/sh\n"), 0700); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		os.Remove(filepath.Join(path, "run.sh"))
+	}
+}
+
+func entryDirectories(entries []dto.EnricherLoadEntry) []string {
+	directories := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		directories = append(directories, entry.Directory)
+	}
+	return directories
+}
+
+func loadedNames(manager *enricherManager) []string {
+	var names []string
+	for _, enricher := range manager.Enrichers()[dto.DOMAIN] {
+		names = append(names, enricher.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestLoadEnrichersReport(t *testing.T) {
+	enrichersPath := t.TempDir()
+	writeEnricher(t, enrichersPath, "whois", "whois", true)
+	writeEnricher(t, enrichersPath, "whois-copy", "whois", true)
+	writeEnricher(t, enrichersPath, "broken", "broken", false)
+	if err := os.Mkdir(filepath.Join(enrichersPath, "empty"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(enrichersPath, "README.md"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewEnricherManager(configs.EnrichersConfig{})
+	if err := manager.LoadEnrichers(enrichersPath); err != nil {
+		t.Fatalf("LoadEnrichers() error = %v", err)
+	}
+	report := manager.LoadReport()
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"loaded", entryDirectories(report.Loaded), []string{"whois"}},
+		{"skipped", entryDirectories(report.Skipped), []string{"README.md", "empty"}},
+		{"invalid", entryDirectories(report.Invalid), []string{"broken", "whois-copy"}},
+		{"enrichers", loadedNames(manager), []string{"whois"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if report.Rejected != "" {
+		t.Errorf("report rejected = %q, want none", report.Rejected)
+	}
+	for _, entry := range report.Invalid {
+		if entry.Reason == "" {
+			t.Errorf("invalid enricher %s has no reason", entry.Directory)
+		}
+	}
+}
+
+func TestReloadEnrichers(t *testing.T) {
+	tests := []struct {
+		name          string
+		strict        bool
+		wantErr       bool
+		wantEnrichers []string
+	}{
+		{
+			name:          "invalid enrichers are reported",
+			wantEnrichers: []string{"dns", "whois"},
+		},
+		{
+			name:          "strict mode rejects the reload",
+			strict:        true,
+			wantErr:       true,
+			wantEnrichers: []string{"whois"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enrichersPath := t.TempDir()
+			writeEnricher(t, enrichersPath, "whois", "whois", true)
+
+			manager := NewEnricherManager(configs.EnrichersConfig{Path: enrichersPath, Strict: tt.strict})
+			if err := manager.LoadEnrichers(enrichersPath); err != nil {
+				t.Fatalf("LoadEnrichers() error = %v", err)
+			}
+
+			writeEnricher(t, enrichersPath, "whois", "whois", false)
+			writeEnricher(t, enrichersPath, "dns", "dns", true)
+
+			report, err := manager.ReloadEnrichers()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReloadEnrichers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (report.Rejected != "") != tt.wantErr {
+				t.Errorf("report rejected = %q, wantErr %v", report.Rejected, tt.wantErr)
+			}
+			if invalid := entryDirectories(report.Invalid); !slices.Equal(invalid, []string{"whois"}) {
+				t.Fatalf("invalid enrichers = %v, want [whois]", invalid)
+			}
+			if !report.Invalid[0].KeptPrevious {
+				t.Error("previous whois version not kept")
+			}
+			if names := loadedNames(manager); !slices.Equal(names, tt.wantEnrichers) {
+				t.Errorf("enrichers = %v, want %v", names, tt.wantEnrichers)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// minDetectionConfidence is required from the best candidate unless it
+	// is the only one.
+	minDetectionConfidence = 0.5
+	// minDetectionMargin is required between the two best candidates.
+	minDetectionMargin = 0.2
+)
+
+type detector struct {
+	dataType   dto.EnricherArgType
+	confidence float64
+	matches    func(value string) bool
+	adjust     func(normalized string, confidence float64) float64
+}
+
+// fileExtensions look like top level domains but usually end file names.
+var fileExtensions = []string{"exe", "dll", "doc", "docx", "pdf", "zip", "rar", "js", "txt", "mov", "sh", "bat"}
+
+var detectors = []detector{
+	{dataType: dto.IP, confidence: 0.99},
+	{dataType: dto.CVE, confidence: 0.99},
+	{dataType: dto.CIDR, confidence: 0.95},
+	{dataType: dto.URL, confidence: 0.95, matches: func(value string) bool {
+		return strings.Contains(value, "://")
+	}},
+	{dataType: dto.HASH, confidence: 0.9},
+	{dataType: dto.EMAIL, confidence: 0.9},
+	{dataType: dto.DOMAIN, confidence: 0.8, adjust: func(normalized string, confidence float64) float64 {
+		if slices.Contains(fileExtensions, normalized[strings.LastIndex(normalized, ".")+1:]) {
+			return confidence / 2
+		}
+		return confidence
+	}},
+	{dataType: dto.USERNAME, confidence: 0.3, matches: func(value string) bool {
+		return !strings.ContainsAny(value, " \t\r\n")
+	}},
+}
+
+// Detect classifies the value into the known data types, custom types
+// included. A type is chosen when it is the only candidate, or when the
+// best candidate is confident enough and clearly ahead of the next one.
+// Otherwise the result is ambiguous and only the candidates are returned.
+func Detect(value string) dto.DetectionResult {
+	return CurrentRegistry().Detect(value)
+}
+
+func (registry *Registry) Detect(value string) dto.DetectionResult {
+	result := dto.DetectionResult{
+		Data:       value,
+		Candidates: []dto.DetectionCandidate{},
+	}
+
+	for _, detector := range registry.getDetectors() {
+		if detector.matches != nil && !detector.matches(strings.TrimSpace(value)) {
+			continue
+		}
+		dataType, normalized, err := registry.Resolve(detector.dataType, value)
+		if err != nil {
+			continue
+		}
+		confidence := detector.confidence
+		if detector.adjust != nil {
+			confidence = detector.adjust(normalized, confidence)
+		}
+		result.Candidates = append(result.Candidates, dto.DetectionCandidate{
+			Type:       dataType,
+			Value:      normalized,
+			Confidence: confidence,
+		})
+	}
+
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return result.Candidates[i].Confidence > result.Candidates[j].Confidence
+	})
+
+	if len(result.Candidates) == 0 {
+		result.Ambiguous = true
+		return result
+	}
+
+	best := result.Candidates[0]
+	if len(result.Candidates) > 1 {
+		next := result.Candidates[1]
+		if best.Confidence < minDetectionConfidence || best.Confidence-next.Confidence < minDetectionMargin {
+			result.Ambiguous = true
+			return result
+		}
+	}
+
+	result.Type = best.Type
+	result.Value = best.Value
+	result.Confidence = best.Confidence
+
+	return result
+}
+
+// getDetectors appends the custom types, which are detected by their
+// pattern, before the username fallback.
+func (registry *Registry) getDetectors() []detector {
+	if len(registry.order) == 0 {
+		return detectors
+	}
+
+	fallback := detectors[len(detectors)-1]
+	registryDetectors := append([]detector(nil), detectors[:len(detectors)-1]...)
+	for _, dataType := range registry.order {
+		registryDetectors = append(registryDetectors, detector{
+			dataType:   dataType,
+			confidence: registry.types[dataType].confidence(),
+		})
+	}
+
+	return append(registryDetectors, fallback)
+}
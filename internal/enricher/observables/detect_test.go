@@ -0,0 +1,62 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		wantType       dto.EnricherArgType
+		wantValue      string
+		wantConfidence float64
+		wantAmbiguous  bool
+	}{
+		{"ipv4", "8.8.8.8", dto.IPV4, "8.8.8.8", 0.99, false},
+		{"ipv6", "2001:db8::1", dto.IPV6, "2001:db8::1", 0.99, false},
+		{"cve", "cve-2021-44228", dto.CVE, "CVE-2021-44228", 0.99, false},
+		{"cidr", "10.1.2.3/8", dto.CIDR, "10.0.0.0/8", 0.95, false},
+		{"url", "https://Example.com:443", dto.URL, "https://example.com/", 0.95, false},
+		{"sha256", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", dto.SHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", 0.9, false},
+		{"email", "john@example.com", dto.EMAIL, "john@example.com", 0.9, false},
+		{"domain", "Example.com", dto.DOMAIN, "example.com", 0.8, false},
+		{"username fallback", "john_doe", dto.USERNAME, "john_doe", 0.3, false},
+		{"file name is ambiguous", "invoice.exe", "", "", 0, true},
+		{"whitespace only", "   ", "", "", 0, true},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := registry.Detect(tt.value)
+			if got.Ambiguous != tt.wantAmbiguous {
+				t.Fatalf("Detect() ambiguous = %v, want %v (candidates %v)", got.Ambiguous, tt.wantAmbiguous, got.Candidates)
+			}
+			if got.Type != tt.wantType || got.Value != tt.wantValue || got.Confidence != tt.wantConfidence {
+				t.Errorf("Detect() = %s %q (%v), want %s %q (%v)", got.Type, got.Value, got.Confidence, tt.wantType, tt.wantValue, tt.wantConfidence)
+			}
+			if got.Data != tt.value {
+				t.Errorf("Detect() data = %q, want %q", got.Data, tt.value)
+			}
+		})
+	}
+}
+
+func TestDetectCandidatesOrder(t *testing.T) {
+	got := NewRegistry().Detect("invoice.exe")
+
+	want := []dto.DetectionCandidate{
+		{Type: dto.DOMAIN, Value: "invoice.exe", Confidence: 0.4},
+		{Type: dto.USERNAME, Value: "invoice.exe", Confidence: 0.3},
+	}
+	if len(got.Candidates) != len(want) {
+		t.Fatalf("Detect() candidates = %v, want %v", got.Candidates, want)
+	}
+	for i := range want {
+		if got.Candidates[i] != want[i] {
+			t.Errorf("Detect() candidate %d = %v, want %v", i, got.Candidates[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownType       = errors.New("unknown data type")
+	ErrInvalidObservable = errors.New("invalid observable")
+)
+
+type normalizer func(value string) (string, error)
+
+var normalizers = map[dto.EnricherArgType]normalizer{
+	dto.URL:      normalizeURL,
+	dto.HASH:     normalizeHash,
+	dto.MD5:      hexHashNormalizer(32),
+	dto.SHA1:     hexHashNormalizer(40),
+	dto.SHA256:   hexHashNormalizer(64),
+	dto.SSDEEP:   normalizeSSDEEP,
+	dto.FILE:     normalizeNotEmpty,
+	dto.USERNAME: normalizeNotEmpty,
+	dto.IP:       normalizeIP,
+	dto.IPV4:     normalizeIPV4,
+	dto.IPV6:     normalizeIPV6,
+	dto.CIDR:     normalizeCIDR,
+	dto.DOMAIN:   normalizeDomain,
+	dto.EMAIL:    normalizeEmail,
+	dto.CVE:      normalizeCVE,
+}
+
+// parents maps the specific types to the generic type matching them, an
+// enricher allowing the generic type handles all of its subtypes.
+var parents = map[dto.EnricherArgType]dto.EnricherArgType{
+	dto.IPV4:   dto.IP,
+	dto.IPV6:   dto.IP,
+	dto.MD5:    dto.HASH,
+	dto.SHA1:   dto.HASH,
+	dto.SHA256: dto.HASH,
+	dto.SSDEEP: dto.HASH,
+}
+
+var (
+	hexPattern    = regexp.MustCompile(`^[0-9a-f]+$`)
+	ssdeepPattern = regexp.MustCompile(`^[0-9]+:[0-9A-Za-z/+]+:[0-9A-Za-z/+]+$`)
+	domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	emailPattern  = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+	cvePattern    = regexp.MustCompile(`^CVE-[0-9]{4}-[0-9]{4,}$`)
+)
+
+var hashTypesByLength = map[int]dto.EnricherArgType{
+	32:  dto.MD5,
+	40:  dto.SHA1,
+	64:  dto.SHA256,
+	128: dto.HASH,
+}
+
+func IsKnownType(dataType dto.EnricherArgType) bool {
+	return CurrentRegistry().IsKnownType(dataType)
+}
+
+func KnownTypes() []dto.EnricherArgType {
+	return CurrentRegistry().KnownTypes()
+}
+
+func builtinTypes() []dto.EnricherArgType {
+	types := make([]dto.EnricherArgType, 0, len(normalizers))
+	for dataType := range normalizers {
+		types = append(types, dataType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// Subtypes returns the specific types matched by the generic data type.
+func Subtypes(dataType dto.EnricherArgType) []dto.EnricherArgType {
+	var subtypes []dto.EnricherArgType
+	for subtype, parent := range parents {
+		if parent == dataType {
+			subtypes = append(subtypes, subtype)
+		}
+	}
+	sort.Slice(subtypes, func(i, j int) bool {
+		return subtypes[i] < subtypes[j]
+	})
+	return subtypes
+}
+
+// Parent returns the generic type matching the data type, empty when the
+// type has none.
+func Parent(dataType dto.EnricherArgType) dto.EnricherArgType {
+	return parents[dataType]
+}
+
+// Matches reports whether the data type or its generic type is allowed.
+func Matches(allowedTypes []dto.EnricherArgType, dataType dto.EnricherArgType) bool {
+	parent, hasParent := parents[dataType]
+	for _, allowedType := range allowedTypes {
+		if allowedType == dataType || (hasParent && allowedType == parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize validates the value of the data type and returns its canonical
+// form, which is used for execution and as the cache key.
+func Normalize(dataType dto.EnricherArgType, value string) (string, error) {
+	return CurrentRegistry().Normalize(dataType, value)
+}
+
+// Resolve normalizes the value and refines a generic type to the specific
+// one of the value, e.g. IP to IPV4 or HASH to SHA256.
+func Resolve(dataType dto.EnricherArgType, value string) (dto.EnricherArgType, string, error) {
+	return CurrentRegistry().Resolve(dataType, value)
+}
+
+func (registry *Registry) Normalize(dataType dto.EnricherArgType, value string) (string, error) {
+	normalize, exists := registry.getNormalizer(dataType)
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnknownType, dataType)
+	}
+
+	normalized, err := normalize(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("%w %s of type %s: %v", ErrInvalidObservable, value, dataType, err)
+	}
+	return normalized, nil
+}
+
+func (registry *Registry) Resolve(dataType dto.EnricherArgType, value string) (dto.EnricherArgType, string, error) {
+	normalized, err := registry.Normalize(dataType, value)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch dataType {
+	case dto.IP:
+		if netip.MustParseAddr(normalized).Is4() {
+			return dto.IPV4, normalized, nil
+		}
+		return dto.IPV6, normalized, nil
+	case dto.HASH:
+		if ssdeepPattern.MatchString(normalized) {
+			return dto.SSDEEP, normalized, nil
+		}
+		return hashTypesByLength[len(normalized)], normalized, nil
+	default:
+		return dataType, normalized, nil
+	}
+}
+
+func normalizeNotEmpty(value string) (string, error) {
+	if value == "" {
+		return "", errors.New("empty value")
+	}
+	return value, nil
+}
+
+// normalizeURL lowercases the scheme and host, drops the default port and
+// the fragment, and uses / for an empty path.
+func normalizeURL(value string) (string, error) {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", errors.New("scheme and host are required")
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	parsed.Host = host
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	return parsed.String(), nil
+}
+
+func normalizeHash(value string) (string, error) {
+	if ssdeepPattern.MatchString(value) {
+		return value, nil
+	}
+
+	value = strings.ToLower(value)
+	if !hexPattern.MatchString(value) {
+		return "", errors.New("hash must be hexadecimal or ssdeep")
+	}
+	if _, exists := hashTypesByLength[len(value)]; !exists {
+		return "", fmt.Errorf("unexpected hash length %d", len(value))
+	}
+	return value, nil
+}
+
+func hexHashNormalizer(length int) normalizer {
+	return func(value string) (string, error) {
+		value = strings.ToLower(value)
+		if !hexPattern.MatchString(value) {
+			return "", errors.New("hash must be hexadecimal")
+		}
+		if len(value) != length {
+			return "", fmt.Errorf("hash length must be %d", length)
+		}
+		return value, nil
+	}
+}
+
+func normalizeSSDEEP(value string) (string, error) {
+	if !ssdeepPattern.MatchString(value) {
+		return "", errors.New("not a ssdeep hash")
+	}
+	return value, nil
+}
+
+func normalizeIP(value string) (string, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || addr.Zone() != "" {
+		return "", errors.New("not an IP address")
+	}
+	return addr.String(), nil
+}
+
+func normalizeIPV4(value string) (string, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is4() {
+		return "", errors.New("not an IPv4 address")
+	}
+	return addr.String(), nil
+}
+
+func normalizeIPV6(value string) (string, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is6() || addr.Zone() != "" {
+		return "", errors.New("not an IPv6 address")
+	}
+	return addr.String(), nil
+}
+
+// normalizeCIDR clears the host bits, 10.1.2.3/8 becomes 10.0.0.0/8.
+func normalizeCIDR(value string) (string, error) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return "", errors.New("not a CIDR range")
+	}
+	return prefix.Masked().String(), nil
+}
+
+func normalizeDomain(value string) (string, error) {
+	value = strings.TrimSuffix(strings.ToLower(value), ".")
+	if len(value) > 253 || !domainPattern.MatchString(value) {
+		return "", errors.New("not a domain name")
+	}
+	return value, nil
+}
+
+// normalizeEmail lowercases the domain, the local part is kept as is.
+func normalizeEmail(value string) (string, error) {
+	if !emailPattern.MatchString(value) {
+		return "", errors.New("not an email address")
+	}
+
+	at := strings.LastIndex(value, "@")
+	domain, err := normalizeDomain(value[at+1:])
+	if err != nil {
+		return "", err
+	}
+	return value[:at+1] + domain, nil
+}
+
+func normalizeCVE(value string) (string, error) {
+	value = strings.ToUpper(value)
+	if !cvePattern.MatchString(value) {
+		return "", errors.New("not a CVE identifier")
+	}
+	return value, nil
+}
@@ -0,0 +1,135 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType dto.EnricherArgType
+		value    string
+		want     string
+		wantErr  error
+	}{
+		{"url lowercases scheme and host", dto.URL, "HTTP://Example.COM/Path", "http://example.com/Path", nil},
+		{"url drops default port", dto.URL, "https://example.com:443/a", "https://example.com/a", nil},
+		{"url keeps other port", dto.URL, "http://example.com:8080/a", "http://example.com:8080/a", nil},
+		{"url adds root path", dto.URL, "http://example.com", "http://example.com/", nil},
+		{"url drops fragment", dto.URL, "http://example.com/a#top", "http://example.com/a", nil},
+		{"url without host", dto.URL, "example.com/a", "", ErrInvalidObservable},
+		{"md5 is lowercased", dto.MD5, "D41D8CD98F00B204E9800998ECF8427E", "d41d8cd98f00b204e9800998ecf8427e", nil},
+		{"md5 wrong length", dto.MD5, "d41d8cd98f00b204", "", ErrInvalidObservable},
+		{"sha1 not hexadecimal", dto.SHA1, "z9ee86e9f3ab2e0b4b0b2f7f0b7c8e1f7f3a2b1c", "", ErrInvalidObservable},
+		{"hash unexpected length", dto.HASH, "abcdef", "", ErrInvalidObservable},
+		{"hash ssdeep", dto.HASH, "3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", "3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", nil},
+		{"ip is trimmed", dto.IP, " 8.8.8.8\n", "8.8.8.8", nil},
+		{"ipv6 is compressed", dto.IP, "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1", nil},
+		{"ip with zone", dto.IP, "fe80::1%eth0", "", ErrInvalidObservable},
+		{"ipv4 rejects ipv6", dto.IPV4, "::1", "", ErrInvalidObservable},
+		{"ipv6 rejects ipv4", dto.IPV6, "1.1.1.1", "", ErrInvalidObservable},
+		{"cidr clears host bits", dto.CIDR, "10.1.2.3/8", "10.0.0.0/8", nil},
+		{"domain lowercased without trailing dot", dto.DOMAIN, "Example.COM.", "example.com", nil},
+		{"domain without tld", dto.DOMAIN, "localhost", "", ErrInvalidObservable},
+		{"email keeps local part", dto.EMAIL, "John.Doe@Example.COM", "John.Doe@example.com", nil},
+		{"email without domain", dto.EMAIL, "john@", "", ErrInvalidObservable},
+		{"cve is uppercased", dto.CVE, "cve-2021-44228", "CVE-2021-44228", nil},
+		{"cve short sequence", dto.CVE, "CVE-2021-442", "", ErrInvalidObservable},
+		{"username empty", dto.USERNAME, "   ", "", ErrInvalidObservable},
+		{"unknown type", "WALLET", "0x00", "", ErrUnknownType},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Normalize(tt.dataType, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType dto.EnricherArgType
+		value    string
+		wantType dto.EnricherArgType
+		wantErr  bool
+	}{
+		{"ipv4", dto.IP, "8.8.8.8", dto.IPV4, false},
+		{"ipv6", dto.IP, "::1", dto.IPV6, false},
+		{"md5", dto.HASH, "d41d8cd98f00b204e9800998ecf8427e", dto.MD5, false},
+		{"sha1", dto.HASH, "da39a3ee5e6b4b0d3255bfef95601890afd80709", dto.SHA1, false},
+		{"sha256", dto.HASH, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", dto.SHA256, false},
+		{"sha512 stays generic", dto.HASH, "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", dto.HASH, false},
+		{"ssdeep", dto.HASH, "3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", dto.SSDEEP, false},
+		{"specific type is kept", dto.SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709", dto.SHA1, false},
+		{"other type is kept", dto.DOMAIN, "example.com", dto.DOMAIN, false},
+		{"invalid value", dto.IP, "300.1.1.1", "", true},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, _, err := registry.Resolve(tt.dataType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotType != tt.wantType {
+				t.Errorf("Resolve() type = %s, want %s", gotType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedTypes []dto.EnricherArgType
+		dataType     dto.EnricherArgType
+		want         bool
+	}{
+		{"same type", []dto.EnricherArgType{dto.DOMAIN}, dto.DOMAIN, true},
+		{"generic type allows subtype", []dto.EnricherArgType{dto.IP}, dto.IPV4, true},
+		{"generic hash allows ssdeep", []dto.EnricherArgType{dto.HASH}, dto.SSDEEP, true},
+		{"subtype does not allow generic type", []dto.EnricherArgType{dto.IPV4}, dto.IP, false},
+		{"sibling subtype", []dto.EnricherArgType{dto.IPV4}, dto.IPV6, false},
+		{"unrelated type", []dto.EnricherArgType{dto.URL, dto.EMAIL}, dto.DOMAIN, false},
+		{"no allowed types", nil, dto.URL, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.allowedTypes, tt.dataType); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtypes(t *testing.T) {
+	tests := []struct {
+		dataType dto.EnricherArgType
+		want     []dto.EnricherArgType
+	}{
+		{dto.IP, []dto.EnricherArgType{dto.IPV4, dto.IPV6}},
+		{dto.HASH, []dto.EnricherArgType{dto.MD5, dto.SHA1, dto.SHA256, dto.SSDEEP}},
+		{dto.DOMAIN, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dataType), func(t *testing.T) {
+			if got := Subtypes(tt.dataType); !slices.Equal(got, tt.want) {
+				t.Errorf("Subtypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+const defaultCustomTypeConfidence = 0.7
+
+var typeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+var normalizations = map[string]func(value string) string{
+	"lowercase": strings.ToLower,
+	"uppercase": strings.ToUpper,
+	"removeWhitespace": func(value string) string {
+		return strings.Join(strings.Fields(value), "")
+	},
+}
+
+type customType struct {
+	definition dto.ObservableTypeDefinition
+	pattern    *regexp.Regexp
+}
+
+// Registry holds the custom types declared by the enrichers next to the
+// built-in ones. The current registry is replaced on every enrichers load.
+type Registry struct {
+	types map[dto.EnricherArgType]customType
+	order []dto.EnricherArgType
+}
+
+var current atomic.Pointer[Registry]
+
+func init() {
+	current.Store(NewRegistry())
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[dto.EnricherArgType]customType)}
+}
+
+func CurrentRegistry() *Registry {
+	return current.Load()
+}
+
+func SetRegistry(registry *Registry) {
+	current.Store(registry)
+}
+
+// Register adds the custom type. The pattern has to match the whole
+// normalized value. Registering the same definition again is a no-op.
+func (registry *Registry) Register(definition dto.ObservableTypeDefinition) error {
+	if !typeNamePattern.MatchString(string(definition.Name)) || definition.Name == dto.AutoDetectType {
+		return fmt.Errorf("invalid custom type name: %q", definition.Name)
+	}
+	if _, exists := normalizers[definition.Name]; exists {
+		return fmt.Errorf("custom type %s redefines a built-in type", definition.Name)
+	}
+	if registered, exists := registry.types[definition.Name]; exists {
+		if reflect.DeepEqual(registered.definition, definition) {
+			return nil
+		}
+		return fmt.Errorf("custom type %s is already defined differently", definition.Name)
+	}
+
+	if definition.Pattern == "" {
+		return fmt.Errorf("custom type %s pattern is required", definition.Name)
+	}
+	pattern, err := regexp.Compile("^(?:" + definition.Pattern + ")$")
+	if err != nil {
+		return fmt.Errorf("custom type %s pattern is invalid: %w", definition.Name, err)
+	}
+	for _, normalization := range definition.Normalize {
+		if _, exists := normalizations[normalization]; !exists {
+			return fmt.Errorf("custom type %s uses unknown normalization: %s", definition.Name, normalization)
+		}
+	}
+	if definition.Confidence < 0 || definition.Confidence > 1 {
+		return fmt.Errorf("custom type %s confidence must be between 0 and 1", definition.Name)
+	}
+
+	registry.types[definition.Name] = customType{definition: definition, pattern: pattern}
+	registry.order = append(registry.order, definition.Name)
+
+	return nil
+}
+
+func (registry *Registry) IsKnownType(dataType dto.EnricherArgType) bool {
+	if _, exists := registry.types[dataType]; exists {
+		return true
+	}
+	_, exists := normalizers[dataType]
+	return exists
+}
+
+func (registry *Registry) KnownTypes() []dto.EnricherArgType {
+	return append(builtinTypes(), registry.order...)
+}
+
+func (registry *Registry) getNormalizer(dataType dto.EnricherArgType) (normalizer, bool) {
+	if custom, exists := registry.types[dataType]; exists {
+		return custom.normalize, true
+	}
+	normalize, exists := normalizers[dataType]
+	return normalize, exists
+}
+
+func (custom customType) normalize(value string) (string, error) {
+	for _, normalization := range custom.definition.Normalize {
+		value = normalizations[normalization](value)
+	}
+	if !custom.pattern.MatchString(value) {
+		return "", errors.New("value does not match the type pattern")
+	}
+	return value, nil
+}
+
+func (custom customType) confidence() float64 {
+	if custom.definition.Confidence > 0 {
+		return custom.definition.Confidence
+	}
+	return defaultCustomTypeConfidence
+}
@@ -0,0 +1,91 @@
+package observables
+
+import (
+	"enricher/internal/enricher/dto"
+	"testing"
+)
+
+var walletType = dto.ObservableTypeDefinition{
+	Name:       "WALLET",
+	Pattern:    `0x[0-9a-f]{40}`,
+	Normalize:  []string{"removeWhitespace", "lowercase"},
+	Confidence: 0.85,
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition dto.ObservableTypeDefinition
+		wantErr    bool
+	}{
+		{"valid", walletType, false},
+		{"same definition again", walletType, false},
+		{"defined differently", dto.ObservableTypeDefinition{Name: "WALLET", Pattern: `0x[0-9a-f]+`}, true},
+		{"default confidence", dto.ObservableTypeDefinition{Name: "TICKET", Pattern: `[A-Z]+-[0-9]+`}, false},
+		{"invalid name", dto.ObservableTypeDefinition{Name: "1WALLET", Pattern: `.+`}, true},
+		{"auto detection name", dto.ObservableTypeDefinition{Name: dto.AutoDetectType, Pattern: `.+`}, true},
+		{"built-in type", dto.ObservableTypeDefinition{Name: dto.DOMAIN, Pattern: `.+`}, true},
+		{"missing pattern", dto.ObservableTypeDefinition{Name: "EMPTY"}, true},
+		{"invalid pattern", dto.ObservableTypeDefinition{Name: "BROKEN", Pattern: `([a-z`}, true},
+		{"unknown normalization", dto.ObservableTypeDefinition{Name: "TRIMMED", Pattern: `.+`, Normalize: []string{"trim"}}, true},
+		{"negative confidence", dto.ObservableTypeDefinition{Name: "LOW", Pattern: `.+`, Confidence: -0.1}, true},
+		{"confidence above one", dto.ObservableTypeDefinition{Name: "HIGH", Pattern: `.+`, Confidence: 1.5}, true},
+	}
+
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.definition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if registered := registry.IsKnownType(tt.definition.Name); !tt.wantErr && !registered {
+				t.Errorf("IsKnownType(%s) = false after Register()", tt.definition.Name)
+			}
+		})
+	}
+
+	if got, want := len(registry.KnownTypes()), len(builtinTypes())+2; got != want {
+		t.Errorf("KnownTypes() has %d types, want %d", got, want)
+	}
+}
+
+func TestRegistryCustomType(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(walletType); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		value        string
+		want         string
+		wantErr      bool
+		wantDetected dto.EnricherArgType
+	}{
+		{"normalized", " 0xAB12 34cd56ef7890ab12cd34ef56ab78cd90ef12 ", "0xab1234cd56ef7890ab12cd34ef56ab78cd90ef12", false, "WALLET"},
+		{"pattern matches the whole value", "0xab1234cd56ef7890ab12cd34ef56ab78cd90ef12ff", "", true, dto.USERNAME},
+		{"not matching", "wallet", "", true, dto.USERNAME},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Normalize("WALLET", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+
+			detected := registry.Detect(tt.value)
+			if detected.Type != tt.wantDetected {
+				t.Errorf("Detect() = %s, want %s", detected.Type, tt.wantDetected)
+			}
+		})
+	}
+
+	if NewRegistry().IsKnownType("WALLET") {
+		t.Error("custom type is known by a new registry")
+	}
+}
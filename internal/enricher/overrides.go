@@ -0,0 +1,105 @@
+package enricher
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func loadOverrides(path string) (map[string]dto.EnricherOverride, error) {
+	overrides := make(map[string]dto.EnricherOverride)
+	if path == "" {
+		return overrides, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return overrides, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read enricher overrides file error: %w", err)
+	}
+
+	if err := json.Unmarshal(file, &overrides); err != nil {
+		return nil, fmt.Errorf("unmarshal enricher overrides error: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// saveOverrides writes the overrides to a temporary file which then
+// replaces the previous one, so a failed write never corrupts it.
+func saveOverrides(path string, overrides map[string]dto.EnricherOverride) error {
+	if path == "" {
+		return nil
+	}
+
+	value, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding enricher overrides error: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create enricher overrides file error: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(value); err != nil {
+		file.Close()
+		return fmt.Errorf("write enricher overrides file error: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write enricher overrides file error: %w", err)
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func mergeOverride(override dto.EnricherOverride, update dto.EnricherOverride) dto.EnricherOverride {
+	if update.Enabled != nil {
+		override.Enabled = update.Enabled
+	}
+	if update.Timeout != nil {
+		override.Timeout = update.Timeout
+	}
+	if update.CacheTTL != nil {
+		override.CacheTTL = update.CacheTTL
+	}
+	return override
+}
+
+func validateOverride(override dto.EnricherOverride) error {
+	if override.Timeout != nil && *override.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if override.CacheTTL != nil && *override.CacheTTL < 0 {
+		return errors.New("cacheTTL must not be negative")
+	}
+	return nil
+}
+
+func applyOverrides(directories map[string]dto.Enricher, overrides map[string]dto.EnricherOverride) map[string]dto.Enricher {
+	overridden := make(map[string]dto.Enricher, len(directories))
+
+	for directory, enricher := range directories {
+		override, exists := overrides[enricher.Name]
+		if exists {
+			if override.Enabled != nil {
+				enricher.Enabled = *override.Enabled
+			}
+			if override.Timeout != nil {
+				enricher.Timeout = *override.Timeout
+			}
+			if override.CacheTTL != nil {
+				enricher.CacheTTL = *override.CacheTTL
+			}
+		}
+		overridden[directory] = enricher
+	}
+
+	return overridden
+}
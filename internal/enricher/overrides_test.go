@@ -0,0 +1,120 @@
+package enricher
+
+import (
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateEnricherPersistsOverrides(t *testing.T) {
+	enrichersPath := t.TempDir()
+	writeEnricher(t, enrichersPath, "whois", "whois", true)
+	config := configs.EnrichersConfig{Path: enrichersPath, OverridesPath: filepath.Join(t.TempDir(), "overrides.json")}
+
+	manager := NewEnricherManager(config)
+	if err := manager.LoadEnrichers(enrichersPath); err != nil {
+		t.Fatalf("LoadEnrichers() error = %v", err)
+	}
+
+	disabled, timeout, cacheTTL := false, int64(30), int64(600)
+	updates := []dto.EnricherOverride{
+		{Enabled: &disabled, Timeout: &timeout},
+		{CacheTTL: &cacheTTL},
+	}
+	for _, update := range updates {
+		if err := manager.UpdateEnricher("whois", update); err != nil {
+			t.Fatalf("UpdateEnricher() error = %v", err)
+		}
+	}
+
+	reloaded := NewEnricherManager(config)
+	if err := reloaded.LoadEnrichers(enrichersPath); err != nil {
+		t.Fatalf("LoadEnrichers() error = %v", err)
+	}
+
+	want := dto.EnricherOverride{Enabled: &disabled, Timeout: &timeout, CacheTTL: &cacheTTL}
+	if !reflect.DeepEqual(reloaded.overrides["whois"], want) {
+		t.Errorf("persisted override = %+v, want %+v", reloaded.overrides["whois"], want)
+	}
+
+	for name, enrichers := range map[string][]dto.Enricher{"updated": manager.Enrichers()[dto.DOMAIN], "reloaded": reloaded.Enrichers()[dto.DOMAIN]} {
+		t.Run(name, func(t *testing.T) {
+			if len(enrichers) != 1 {
+				t.Fatalf("enrichers = %v, want whois", enrichers)
+			}
+			enricher := enrichers[0]
+			if enricher.Enabled || enricher.Timeout != timeout || enricher.CacheTTL != cacheTTL {
+				t.Errorf("enricher enabled = %v, timeout = %d, cacheTTL = %d, want false, %d, %d", enricher.Enabled, enricher.Timeout, enricher.CacheTTL, timeout, cacheTTL)
+			}
+		})
+	}
+}
+
+func TestUpdateEnricherErrors(t *testing.T) {
+	negative := int64(-1)
+
+	tests := []struct {
+		name     string
+		enricher string
+		update   dto.EnricherOverride
+		wantErr  error
+	}{
+		{"unknown enricher", "dns", dto.EnricherOverride{}, ErrEnricherNotFound},
+		{"negative timeout", "whois", dto.EnricherOverride{Timeout: &negative}, ErrInvalidOverride},
+		{"negative cache TTL", "whois", dto.EnricherOverride{CacheTTL: &negative}, ErrInvalidOverride},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enrichersPath := t.TempDir()
+			writeEnricher(t, enrichersPath, "whois", "whois", true)
+			overridesPath := filepath.Join(t.TempDir(), "overrides.json")
+
+			manager := NewEnricherManager(configs.EnrichersConfig{Path: enrichersPath, OverridesPath: overridesPath})
+			if err := manager.LoadEnrichers(enrichersPath); err != nil {
+				t.Fatalf("LoadEnrichers() error = %v", err)
+			}
+
+			if err := manager.UpdateEnricher(tt.enricher, tt.update); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateEnricher() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(overridesPath); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("overrides file written after a failed update: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	directory := t.TempDir()
+	invalidPath := filepath.Join(directory, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"no overrides file configured", "", false},
+		{"missing overrides file", filepath.Join(directory, "missing.json"), false},
+		{"malformed overrides file", invalidPath, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := loadOverrides(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(overrides) != 0 {
+				t.Errorf("loadOverrides() = %v, want none", overrides)
+			}
+		})
+	}
+}
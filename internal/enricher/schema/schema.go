@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Schema is a compiled result schema, it is compiled once when the
+// enricher is loaded.
+type Schema struct {
+	compiled *gojsonschema.Schema
+}
+
+func Compile(schema json.RawMessage) (*Schema, error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid result schema: %w", err)
+	}
+
+	return &Schema{compiled: compiled}, nil
+}
+
+// Validate returns the violations of the value against the schema.
+func (schema *Schema) Validate(value any) ([]string, error) {
+	result, err := schema.compiled.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return nil, fmt.Errorf("result schema validation error: %w", err)
+	}
+
+	violations := make([]string, 0, len(result.Errors()))
+	for _, violation := range result.Errors() {
+		violations = append(violations, violation.String())
+	}
+	return violations, nil
+}
@@ -0,0 +1,206 @@
+package enricher
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const typesManifestName = "types.json"
+
+// loadTypesManifest reads the custom types shared by all the enrichers. A
+// broken manifest fails the load.
+func loadTypesManifest(enrichersPath string) ([]dto.ObservableTypeDefinition, error) {
+	manifest, err := os.ReadFile(filepath.Join(enrichersPath, typesManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read types manifest error: %w", err)
+	}
+
+	var definitions []dto.ObservableTypeDefinition
+	if err := json.Unmarshal(manifest, &definitions); err != nil {
+		return nil, fmt.Errorf("unmarshal types manifest error: %w", err)
+	}
+	if _, err := newTypesRegistry(definitions); err != nil {
+		return nil, fmt.Errorf("types manifest: %w", err)
+	}
+
+	return definitions, nil
+}
+
+// loadObservableTypes builds the registry the plugins are validated with,
+// holding the manifest types and the types declared in the settings.json of
+// the plugins. Plugins declaring an invalid type, or a type another plugin
+// defines differently, are returned by directory to report them invalid;
+// their types are left out of the registry.
+func loadObservableTypes(enrichersPath string, manifest []dto.ObservableTypeDefinition) (*observables.Registry, map[string]error, error) {
+	entries, err := os.ReadDir(enrichersPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typeErrors := make(map[string]error)
+	declared := make(map[string][]dto.ObservableTypeDefinition)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		file, err := os.ReadFile(filepath.Join(enrichersPath, entry.Name(), "settings.json"))
+		if err != nil {
+			continue
+		}
+		var settings struct {
+			Types []dto.ObservableTypeDefinition `json:"types"`
+		}
+		if err := json.Unmarshal(file, &settings); err != nil || len(settings.Types) == 0 {
+			continue
+		}
+
+		if _, err := newTypesRegistry(append(append([]dto.ObservableTypeDefinition(nil), manifest...), settings.Types...)); err != nil {
+			typeErrors[entry.Name()] = err
+			continue
+		}
+		declared[entry.Name()] = settings.Types
+	}
+
+	for directory, err := range getTypeConflicts(declared) {
+		typeErrors[directory] = err
+	}
+
+	definitions := append([]dto.ObservableTypeDefinition(nil), manifest...)
+	for _, directory := range sortedKeys(declared) {
+		if _, exists := typeErrors[directory]; !exists {
+			definitions = append(definitions, declared[directory]...)
+		}
+	}
+
+	registry, err := newTypesRegistry(definitions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return registry, typeErrors, nil
+}
+
+// getTypeConflicts reports every plugin declaring a type which another
+// plugin defines differently, so the directory order never picks a winner.
+func getTypeConflicts(declared map[string][]dto.ObservableTypeDefinition) map[string]error {
+	type declaration struct {
+		directory  string
+		definition dto.ObservableTypeDefinition
+	}
+
+	byName := make(map[dto.EnricherArgType][]declaration)
+	for _, directory := range sortedKeys(declared) {
+		for _, definition := range declared[directory] {
+			byName[definition.Name] = append(byName[definition.Name], declaration{directory, definition})
+		}
+	}
+
+	conflicts := make(map[string]error)
+	for name, declarations := range byName {
+		conflicting := false
+		for _, other := range declarations[1:] {
+			if !reflect.DeepEqual(declarations[0].definition, other.definition) {
+				conflicting = true
+				break
+			}
+		}
+		if !conflicting {
+			continue
+		}
+
+		directories := make([]string, 0, len(declarations))
+		for _, declaration := range declarations {
+			directories = append(directories, declaration.directory)
+		}
+		for _, declaration := range declarations {
+			conflicts[declaration.directory] = fmt.Errorf("custom type %s is defined differently by the plugins in %s", name, strings.Join(directories, ", "))
+		}
+	}
+
+	return conflicts
+}
+
+// validateTypes returns the directories whose enrichers use a custom type
+// which neither the manifest nor any of the loaded enrichers defines.
+func validateTypes(directories map[string]dto.Enricher, manifest []dto.ObservableTypeDefinition) map[string]error {
+	defined := make(map[dto.EnricherArgType]bool)
+	for _, definition := range manifest {
+		defined[definition.Name] = true
+	}
+	for _, enricher := range directories {
+		for _, definition := range enricher.Types {
+			defined[definition.Name] = true
+		}
+	}
+
+	builtin := observables.NewRegistry()
+	invalid := make(map[string]error)
+
+	for directory, enricher := range directories {
+		used := append([]dto.EnricherArgType(nil), enricher.AllowedTypes...)
+		for dataType := range enricher.CacheTTLByType {
+			used = append(used, dataType)
+		}
+
+		for _, dataType := range used {
+			if !builtin.IsKnownType(dataType) && !defined[dataType] {
+				invalid[directory] = fmt.Errorf("custom type %s is not defined by any loaded enricher", dataType)
+				break
+			}
+		}
+	}
+
+	return invalid
+}
+
+// buildTypesRegistry holds the manifest types and the types of the enabled
+// enrichers, requests of the types nothing can enrich are rejected.
+func buildTypesRegistry(manifest []dto.ObservableTypeDefinition, directories map[string]dto.Enricher) *observables.Registry {
+	definitions := append([]dto.ObservableTypeDefinition(nil), manifest...)
+	for _, directory := range sortedKeys(directories) {
+		if enricher := directories[directory]; enricher.Enabled {
+			definitions = append(definitions, enricher.Types...)
+		}
+	}
+
+	registry, err := newTypesRegistry(definitions)
+	if err != nil {
+		log.Printf("Error registering enricher types: %v", err)
+	}
+	return registry
+}
+
+// newTypesRegistry registers the definitions in order, stopping at the
+// first one which can not be registered.
+func newTypesRegistry(definitions []dto.ObservableTypeDefinition) (*observables.Registry, error) {
+	registry := observables.NewRegistry()
+	for _, definition := range definitions {
+		if err := registry.Register(definition); err != nil {
+			return registry, err
+		}
+	}
+	return registry, nil
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,7 +1,9 @@
 package enricher
 
 import (
+	"enricher/internal/enricher/args"
 	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
 	"errors"
 	"fmt"
 	"net/url"
@@ -19,27 +21,6 @@ func isValidURL(str string) bool {
 	return true
 }
 
-func validateEnricherArgType(value any, requiredType dto.EnricherConfigArgType) error {
-
-	ok := true
-	switch requiredType {
-	case dto.StringArg:
-		_, ok = value.(string)
-	case dto.IntArg:
-		_, ok = value.(int)
-	case dto.BoolArg:
-		_, ok = value.(bool)
-	case dto.FloatArg:
-		_, ok = value.(float64)
-	default:
-		return fmt.Errorf("unknown argument type: %s", requiredType)
-	}
-	if !ok {
-		return fmt.Errorf("invalid type for argument: %v, expected %s", value, requiredType)
-	}
-	return nil
-}
-
 func validateEnricherSource(source string) error {
 
 	if len(source) > 0 && !isValidURL(source) {
@@ -59,8 +40,8 @@ func validateEnricherExecutablePath(executablePath string) error {
 
 }
 
-func validateEnricherArgs(args []dto.EnricherConfigArg) error {
-	for _, arg := range args {
+func validateEnricherArgs(configArgs []dto.EnricherConfigArg) error {
+	for _, arg := range configArgs {
 
 		if arg.Required && arg.DefaultValue != nil {
 			return errors.New(fmt.Sprintf("default value provided for required argument: %s", arg.Name))
@@ -70,7 +51,7 @@ func validateEnricherArgs(args []dto.EnricherConfigArg) error {
 			continue
 		}
 
-		err := validateEnricherArgType(arg.DefaultValue, arg.Type)
+		_, err := args.Convert(arg.DefaultValue, arg.Type)
 		if err != nil {
 			return err
 		}
@@ -79,33 +60,89 @@ func validateEnricherArgs(args []dto.EnricherConfigArg) error {
 	return nil
 }
 
-func validateAllowedTypes(enricherTypes []dto.EnricherArgType) error {
+func validateAllowedTypes(enricherTypes []dto.EnricherArgType, registry *observables.Registry) error {
 	if len(enricherTypes) <= 0 {
 		return errors.New(fmt.Sprintf("Enrichers allowed types list cannot be empty"))
 	}
+	for _, enricherType := range enricherTypes {
+		if !registry.IsKnownType(enricherType) {
+			return fmt.Errorf("unknown allowed type: %s", enricherType)
+		}
+	}
+	return nil
+}
+
+func validateEnricherCacheTTL(enricherValue dto.Enricher, registry *observables.Registry) error {
+	if enricherValue.CacheTTL < 0 {
+		return fmt.Errorf("cache TTL cannot be negative: %d", enricherValue.CacheTTL)
+	}
+	for argType, ttl := range enricherValue.CacheTTLByType {
+		if !registry.IsKnownType(argType) {
+			return fmt.Errorf("unknown cache TTL type: %s", argType)
+		}
+		if ttl < 0 {
+			return fmt.Errorf("cache TTL for type %s cannot be negative: %d", argType, ttl)
+		}
+	}
 	return nil
 }
 
-func validateEnricher(enricherValue dto.Enricher) error {
+func validateEnricherProtocol(protocol dto.EnricherProtocol) error {
+	switch protocol {
+	case "", dto.ArgvProtocol, dto.JSONProtocol:
+		return nil
+	default:
+		return fmt.Errorf("unknown enricher protocol: %s", protocol)
+	}
+}
+
+func validateEnricherSchemaPolicy(enricher dto.Enricher) error {
+	switch enricher.SchemaPolicy {
+	case "", dto.RejectSchemaPolicy, dto.WarnSchemaPolicy, dto.PassSchemaPolicy:
+	default:
+		return fmt.Errorf("unknown schema policy: %s", enricher.SchemaPolicy)
+	}
+	return nil
+}
+
+func validateEnricher(enricherValue dto.Enricher, registry *observables.Registry) error {
 	err := validateEnricherExecutablePath(enricherValue.ExecutablePath)
 
 	if err != nil {
 		return err
 	}
 
+	err = validateEnricherProtocol(enricherValue.Protocol)
+
+	if err != nil {
+		return err
+	}
+
 	err = validateEnricherSource(enricherValue.Source)
 
 	if err != nil {
 		return err
 	}
 
+	err = validateEnricherCacheTTL(enricherValue, registry)
+
+	if err != nil {
+		return err
+	}
+
 	err = validateEnricherArgs(enricherValue.ConfigArgs)
 
 	if err != nil {
 		return err
 	}
 
-	err = validateAllowedTypes(enricherValue.AllowedTypes)
+	err = validateAllowedTypes(enricherValue.AllowedTypes, registry)
+
+	if err != nil {
+		return err
+	}
+
+	err = validateEnricherSchemaPolicy(enricherValue)
 
 	if err != nil {
 		return err
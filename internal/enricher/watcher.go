@@ -0,0 +1,82 @@
+package enricher
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const reloadDebounce = 500 * time.Millisecond
+
+func (manager *enricherManager) WatchEnrichers(enrichersPath string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := addWatchDirectories(watcher, enrichersPath); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go manager.watch(watcher, enrichersPath)
+
+	return nil
+}
+
+func addWatchDirectories(watcher *fsnotify.Watcher, enrichersPath string) error {
+	if err := watcher.Add(enrichersPath); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(enrichersPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := watcher.Add(filepath.Join(enrichersPath, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (manager *enricherManager) watch(watcher *fsnotify.Watcher, enrichersPath string) {
+	defer watcher.Close()
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watcher.Add(event.Name); err != nil {
+						log.Printf("Error watching enricher directory %s: %v", event.Name, err)
+					}
+				}
+			}
+			reload = time.After(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Enrichers watcher error: %v", err)
+		case <-reload:
+			reload = nil
+			log.Println("Reloading enrichers...")
+			if err := manager.LoadEnrichers(enrichersPath); err != nil {
+				log.Printf("Error while enrichers reload: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package jobs
+
+import (
+	"context"
+	"enricher/internal/common"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"enricher/internal/enricher/observables"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrEmptyBatch = errors.New("batch has no valid observables")
+
+// SubmitBatch deduplicates the observables and rejects the ones no
+// enricher can be selected for. The inputs already rejected by the caller
+// are kept in the batch summary.
+func (manager *JobManager) SubmitBatch(input dto.BatchInputData, rejected []dto.BatchRejection) (dto.Job, error) {
+	batch := &dto.BatchSummary{
+		Total:    len(input.Observables) + len(rejected),
+		Rejected: rejected,
+	}
+
+	seen := make(map[string]int)
+	// selection errors are cached by the type the enrichers are selected
+	// with, an MD5 and a SHA256 given as HASH may not share enrichers
+	selectionErrors := make(map[dto.EnricherArgType]error)
+
+	for _, batchObservable := range input.Observables {
+		observable := dto.Observable{Value: batchObservable.Data, Type: batchObservable.DataType}
+
+		if index, exists := seen[observable.ID()]; exists {
+			batch.Duplicates++
+			if index >= 0 {
+				batch.Items[index].Duplicates++
+			}
+			continue
+		}
+
+		selectionType, _, err := observables.Resolve(observable.Type, observable.Value)
+		if err != nil {
+			selectionType = observable.Type
+		}
+
+		err, checked := selectionErrors[selectionType]
+		if !checked {
+			data := input.EnricherInputData
+			data.Data = observable.Value
+			data.DataType = observable.Type
+			_, err = manager.executor.SelectEnrichers(data)
+			selectionErrors[selectionType] = err
+		}
+
+		if err != nil {
+			seen[observable.ID()] = -1
+			batch.Rejected = append(batch.Rejected, dto.BatchRejection{
+				Observable: observable.Value,
+				DataType:   observable.Type,
+				Error:      err.Error(),
+			})
+			continue
+		}
+
+		seen[observable.ID()] = len(batch.Items)
+		batch.Items = append(batch.Items, dto.BatchItem{
+			Observable: observable.Value,
+			DataType:   observable.Type,
+			Status:     dto.JobPending,
+		})
+	}
+
+	if len(batch.Items) == 0 {
+		if len(batch.Rejected) > 0 {
+			return dto.Job{}, fmt.Errorf("%w: %s", ErrEmptyBatch, batch.Rejected[0].Error)
+		}
+		return dto.Job{}, ErrEmptyBatch
+	}
+
+	job := &dto.Job{
+		ID:        uuid.NewString(),
+		Status:    dto.JobPending,
+		Input:     input.EnricherInputData,
+		CreatedAt: time.Now(),
+		Batch:     batch,
+	}
+	batch.JobID = job.ID
+	batch.Status = job.Status
+	summarizeBatch(batch)
+
+	snapshot := manager.update(job, func(job *dto.Job) {})
+
+	go manager.run(job)
+
+	return manager.maskJob(snapshot), nil
+}
+
+// runBatch delivers the results of every observable as soon as it is
+// enriched, the batch summary is delivered last. The progress of the items
+// is kept in memory and saved every progressSaveInterval.
+func (manager *JobManager) runBatch(job *dto.Job) {
+	manager.update(job, func(job *dto.Job) {
+		startedAt := time.Now()
+		job.Status = dto.JobRunning
+		job.StartedAt = &startedAt
+		job.Batch.Status = job.Status
+	})
+
+	input := job.Input
+	input.JobID = job.ID
+
+	batch := make([]dto.Observable, len(job.Batch.Items))
+	for i, item := range job.Batch.Items {
+		batch[i] = dto.Observable{Value: item.Observable, Type: item.DataType}
+	}
+
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var deliveryErrs []error
+
+	done := func(index int, results []dto.EnricherResultEnvelope, err error) {
+		manager.modify(job, func(job *dto.Job) {
+			item := &job.Batch.Items[index]
+			setBatchItemResult(item, results, err)
+			countBatchItem(job.Batch, item.Status)
+		})
+
+		if len(results) == 0 || job.Input.WebhookUri == "" {
+			return
+		}
+		deliveryCtx, cancel := manager.webhookContext(ctx)
+		defer cancel()
+
+		outcomes := common.ParallelExecute(deliveryCtx, job.Input.WebhookUri, results, SendEnrichmentResult, manager.webhookConcurrency)
+		if err := common.OutcomesErrors(outcomes); err != nil {
+			mu.Lock()
+			deliveryErrs = append(deliveryErrs, err)
+			mu.Unlock()
+		}
+	}
+
+	stopSaving := manager.saveEvery(job, progressSaveInterval)
+	outcomes := manager.executor.ExecuteBatch(ctx, input, batch, done)
+	stopSaving()
+
+	var results []dto.EnricherResultEnvelope
+	for _, outcome := range outcomes {
+		results = append(results, outcome.Result...)
+	}
+
+	snapshot := manager.update(job, func(job *dto.Job) {
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		job.Results = results
+		for i, outcome := range outcomes {
+			setBatchItemResult(&job.Batch.Items[i], outcome.Result, outcome.Err)
+		}
+		summarizeBatch(job.Batch)
+		job.Status = getBatchStatus(job.Batch)
+		job.Batch.Status = job.Status
+	})
+
+	if job.Input.WebhookUri != "" {
+		deliveryCtx, cancel := manager.webhookContext(ctx)
+		if _, err := SendBatchSummary(deliveryCtx, *snapshot.Batch, job.Input.WebhookUri); err != nil {
+			deliveryErrs = append(deliveryErrs, err)
+		}
+		cancel()
+	}
+
+	deliveryErr := common.MergeErrors(deliveryErrs)
+	if deliveryErr == nil {
+		return
+	}
+	log.Printf("Error sending enriched results of job %s: %v", job.ID, deliveryErr)
+
+	manager.update(job, func(job *dto.Job) {
+		job.Errors = common.SplitErrors(deliveryErr)
+	})
+}
+
+func setBatchItemResult(item *dto.BatchItem, results []dto.EnricherResultEnvelope, err error) {
+	if err != nil {
+		item.Status = dto.JobFailed
+		item.Error = err.Error()
+		return
+	}
+	item.Status = executors.GetExecutionStatus(results)
+	item.Error = ""
+}
+
+func summarizeBatch(batch *dto.BatchSummary) {
+	batch.Unique = len(batch.Items)
+	batch.Succeeded, batch.PartiallySucceeded, batch.Failed = 0, 0, 0
+
+	for _, item := range batch.Items {
+		countBatchItem(batch, item.Status)
+	}
+}
+
+func countBatchItem(batch *dto.BatchSummary, status dto.JobStatus) {
+	switch status {
+	case dto.JobSucceeded:
+		batch.Succeeded++
+	case dto.JobPartiallySucceeded:
+		batch.PartiallySucceeded++
+	case dto.JobFailed:
+		batch.Failed++
+	}
+}
+
+// getBatchStatus counts the rejected inputs as failures, so a batch only
+// succeeds when all of its inputs did.
+func getBatchStatus(batch *dto.BatchSummary) dto.JobStatus {
+	switch {
+	case batch.Succeeded == len(batch.Items) && len(batch.Rejected) == 0:
+		return dto.JobSucceeded
+	case batch.Succeeded == 0 && batch.PartiallySucceeded == 0:
+		return dto.JobFailed
+	default:
+		return dto.JobPartiallySucceeded
+	}
+}
+
+func copyBatch(batch *dto.BatchSummary) *dto.BatchSummary {
+	if batch == nil {
+		return nil
+	}
+	snapshot := *batch
+	snapshot.Items = append([]dto.BatchItem(nil), batch.Items...)
+	snapshot.Rejected = append([]dto.BatchRejection(nil), batch.Rejected...)
+	return &snapshot
+}
+
+func resetBatch(batch *dto.BatchSummary) {
+	for i := range batch.Items {
+		batch.Items[i].Status = dto.JobPending
+		batch.Items[i].Error = ""
+	}
+	batch.Status = dto.JobPending
+	summarizeBatch(batch)
+}
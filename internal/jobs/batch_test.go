@@ -0,0 +1,203 @@
+package jobs
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/common"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"enricher/internal/enricher/observables"
+	"enricher/internal/jobs/store"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// batchExecutor has no enrichers for the CVE observables and the SHA256
+// hashes, the other observables are enriched.
+type batchExecutor struct {
+	executors.EnricherExecutor
+}
+
+func (executor batchExecutor) SelectEnrichers(input dto.EnricherInputData) ([]dto.Enricher, error) {
+	dataType, _, err := observables.Resolve(input.DataType, input.Data)
+	if err != nil {
+		return nil, err
+	}
+	if dataType == dto.CVE || dataType == dto.SHA256 {
+		return nil, fmt.Errorf("no enrichers for %s", dataType)
+	}
+	return []dto.Enricher{{Name: "echo"}}, nil
+}
+
+func (executor batchExecutor) MaskSecretArgs(requestArgs map[string]map[string]any) map[string]map[string]any {
+	return requestArgs
+}
+
+func (executor batchExecutor) ExecuteBatch(ctx context.Context, input dto.EnricherInputData, batch []dto.Observable, done executors.BatchFunc) []common.Outcome[[]dto.EnricherResultEnvelope] {
+	outcomes := make([]common.Outcome[[]dto.EnricherResultEnvelope], len(batch))
+	for i := range batch {
+		outcomes[i].Result = []dto.EnricherResultEnvelope{{Status: dto.JobSucceeded}}
+		done(i, outcomes[i].Result, nil)
+	}
+	return outcomes
+}
+
+const (
+	md5Hash    = "d41d8cd98f00b204e9800998ecf8427e"
+	sha256Hash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func TestSubmitBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		observables    []dto.BatchObservable
+		rejected       []dto.BatchRejection
+		wantErr        error
+		wantTotal      int
+		wantDuplicates int
+		wantItems      map[string]int
+		wantRejected   int
+		wantStatus     dto.JobStatus
+	}{
+		{
+			name: "unique observables",
+			observables: []dto.BatchObservable{
+				{Data: "8.8.8.8", DataType: dto.IPV4},
+				{Data: "example.com", DataType: dto.DOMAIN},
+			},
+			wantTotal:  2,
+			wantItems:  map[string]int{"8.8.8.8": 0, "example.com": 0},
+			wantStatus: dto.JobSucceeded,
+		},
+		{
+			name: "duplicates are merged",
+			observables: []dto.BatchObservable{
+				{Data: "8.8.8.8", DataType: dto.IPV4},
+				{Data: "example.com", DataType: dto.DOMAIN},
+				{Data: "8.8.8.8", DataType: dto.IPV4},
+				{Data: "8.8.8.8", DataType: dto.IPV4},
+			},
+			wantTotal:      4,
+			wantDuplicates: 2,
+			wantItems:      map[string]int{"8.8.8.8": 2, "example.com": 0},
+			wantStatus:     dto.JobSucceeded,
+		},
+		{
+			name: "same value of another type is not a duplicate",
+			observables: []dto.BatchObservable{
+				{Data: "example.com", DataType: dto.DOMAIN},
+				{Data: "example.com", DataType: dto.USERNAME},
+			},
+			wantTotal:  2,
+			wantItems:  map[string]int{"example.com": 0},
+			wantStatus: dto.JobSucceeded,
+		},
+		{
+			name: "unselectable observables are rejected once",
+			observables: []dto.BatchObservable{
+				{Data: "CVE-2021-44228", DataType: dto.CVE},
+				{Data: "8.8.8.8", DataType: dto.IPV4},
+				{Data: "CVE-2021-44228", DataType: dto.CVE},
+			},
+			rejected:       []dto.BatchRejection{{Observable: "???", Error: "unknown type"}},
+			wantTotal:      4,
+			wantDuplicates: 1,
+			wantItems:      map[string]int{"8.8.8.8": 0},
+			wantRejected:   1,
+			wantStatus:     dto.JobPartiallySucceeded,
+		},
+		{
+			name: "unselectable subtype before a selectable one",
+			observables: []dto.BatchObservable{
+				{Data: sha256Hash, DataType: dto.HASH},
+				{Data: md5Hash, DataType: dto.HASH},
+			},
+			wantTotal:    2,
+			wantItems:    map[string]int{md5Hash: 0},
+			wantRejected: 1,
+			wantStatus:   dto.JobPartiallySucceeded,
+		},
+		{
+			name: "selectable subtype before an unselectable one",
+			observables: []dto.BatchObservable{
+				{Data: md5Hash, DataType: dto.HASH},
+				{Data: sha256Hash, DataType: dto.HASH},
+			},
+			wantTotal:    2,
+			wantItems:    map[string]int{md5Hash: 0},
+			wantRejected: 1,
+			wantStatus:   dto.JobPartiallySucceeded,
+		},
+		{
+			name: "no valid observables",
+			observables: []dto.BatchObservable{
+				{Data: "CVE-2021-44228", DataType: dto.CVE},
+			},
+			wantErr: ErrEmptyBatch,
+		},
+		{
+			name:    "empty batch",
+			wantErr: ErrEmptyBatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewJobManager(batchExecutor{}, store.NewInMemoryJobStore(), nil, configs.JobsConfig{})
+
+			job, err := manager.SubmitBatch(dto.BatchInputData{Observables: tt.observables}, tt.rejected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitBatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			batch := job.Batch
+			if batch.Total != tt.wantTotal || batch.Duplicates != tt.wantDuplicates || batch.Unique != len(batch.Items) {
+				t.Errorf("SubmitBatch() total %d, duplicates %d, unique %d, want %d, %d, %d",
+					batch.Total, batch.Duplicates, batch.Unique, tt.wantTotal, tt.wantDuplicates, len(batch.Items))
+			}
+			if len(batch.Rejected) != tt.wantRejected+len(tt.rejected) {
+				t.Errorf("SubmitBatch() rejected %v, want %d", batch.Rejected, tt.wantRejected+len(tt.rejected))
+			}
+			if len(batch.Items) != len(tt.observables)-tt.wantDuplicates-tt.wantRejected {
+				t.Errorf("SubmitBatch() items %v", batch.Items)
+			}
+			for _, item := range batch.Items {
+				if duplicates, exists := tt.wantItems[item.Observable]; !exists || item.Duplicates != duplicates {
+					t.Errorf("item %s has %d duplicates, want %d", item.Observable, item.Duplicates, duplicates)
+				}
+			}
+
+			finished := waitForJob(t, manager, job.ID)
+			if finished.Status != tt.wantStatus || finished.Batch.Status != tt.wantStatus {
+				t.Errorf("batch status = %s (%s), want %s", finished.Status, finished.Batch.Status, tt.wantStatus)
+			}
+			if finished.Batch.Succeeded != len(batch.Items) {
+				t.Errorf("batch succeeded = %d, want %d", finished.Batch.Succeeded, len(batch.Items))
+			}
+		})
+	}
+}
+
+func waitForJob(t *testing.T, manager *JobManager, id string) dto.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, err := manager.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish", id)
+	return dto.Job{}
+}
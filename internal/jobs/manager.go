@@ -0,0 +1,341 @@
+package jobs
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/common"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"enricher/internal/jobs/store"
+	"github.com/google/uuid"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrJobNotFound = store.ErrJobNotFound
+
+// progressSaveInterval is how often the progress of a running job is
+// persisted, saving the job whenever an enricher starts or finishes would
+// make every execution wait for a store write.
+const progressSaveInterval = time.Second
+
+type JobRegistry interface {
+	Submit(input dto.EnricherInputData) (dto.Job, error)
+	SubmitBatch(input dto.BatchInputData, rejected []dto.BatchRejection) (dto.Job, error)
+	GetJob(id string) (dto.Job, error)
+}
+
+type UploadedFiles interface {
+	Exists(path string) bool
+	Remove(path string) error
+	RemoveUnused(used []string) error
+}
+
+type JobManager struct {
+	executor           executors.EnricherExecutor
+	store              store.JobStore
+	files              UploadedFiles
+	retention          time.Duration
+	webhookConcurrency int
+	webhookTimeout     time.Duration
+	mu                 sync.Mutex
+}
+
+func NewJobManager(executor executors.EnricherExecutor, jobStore store.JobStore, files UploadedFiles, config configs.JobsConfig) *JobManager {
+	return &JobManager{
+		executor:           executor,
+		store:              jobStore,
+		files:              files,
+		retention:          time.Duration(config.Retention) * time.Second,
+		webhookConcurrency: config.WebhookConcurrency,
+		webhookTimeout:     time.Duration(config.WebhookTimeout) * time.Second,
+	}
+}
+
+func (manager *JobManager) Submit(input dto.EnricherInputData) (dto.Job, error) {
+	if _, err := manager.executor.SelectEnrichers(input); err != nil {
+		return dto.Job{}, err
+	}
+
+	job := &dto.Job{
+		ID:        uuid.NewString(),
+		Status:    dto.JobPending,
+		Input:     input,
+		CreatedAt: time.Now(),
+	}
+
+	snapshot := manager.update(job, func(job *dto.Job) {})
+
+	go manager.run(job)
+
+	return manager.maskJob(snapshot), nil
+}
+
+func (manager *JobManager) GetJob(id string) (dto.Job, error) {
+
+	job, err := manager.store.Get(id)
+	if err != nil {
+		return dto.Job{}, err
+	}
+	return manager.maskJob(job), nil
+}
+
+func (manager *JobManager) ResumeUnfinishedJobs() error {
+
+	storedJobs, err := manager.store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, storedJob := range storedJobs {
+		if storedJob.IsFinished() {
+			continue
+		}
+
+		job := storedJob
+		if job.Input.File != nil && !manager.files.Exists(job.Input.Data) {
+			log.Printf("Unfinished job %s can not be resumed, its uploaded file is missing", job.ID)
+			manager.update(&job, func(job *dto.Job) {
+				finishedAt := time.Now()
+				job.Status = dto.JobFailed
+				job.FinishedAt = &finishedAt
+				job.Errors = append(job.Errors, "uploaded file is missing, the job can not be resumed")
+			})
+			continue
+		}
+
+		log.Printf("Resuming unfinished job %s", job.ID)
+		manager.update(&job, func(job *dto.Job) {
+			job.Status = dto.JobPending
+			job.StartedAt = nil
+			job.Progress = nil
+			if job.Batch != nil {
+				resetBatch(job.Batch)
+			}
+		})
+
+		go manager.run(&job)
+	}
+
+	return nil
+}
+
+// RemoveUnusedUploads deletes the uploaded files no unfinished job uses,
+// they are left over when the service stops while jobs are running.
+func (manager *JobManager) RemoveUnusedUploads() error {
+
+	storedJobs, err := manager.store.List()
+	if err != nil {
+		return err
+	}
+
+	var used []string
+	for _, job := range storedJobs {
+		if !job.IsFinished() && job.Input.File != nil {
+			used = append(used, job.Input.Data)
+		}
+	}
+
+	return manager.files.RemoveUnused(used)
+}
+
+func (manager *JobManager) StartCleanup() {
+	go func() {
+		ticker := time.NewTicker(manager.cleanupInterval())
+		defer ticker.Stop()
+
+		for range ticker.C {
+			manager.removeExpiredJobs()
+		}
+	}()
+}
+
+func (manager *JobManager) cleanupInterval() time.Duration {
+	interval := manager.retention / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+func (manager *JobManager) removeExpiredJobs() {
+
+	storedJobs, err := manager.store.List()
+	if err != nil {
+		log.Printf("Error listing jobs for cleanup: %v", err)
+		return
+	}
+
+	threshold := time.Now().Add(-manager.retention)
+	var expiredIds []string
+	for _, job := range storedJobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(threshold) {
+			expiredIds = append(expiredIds, job.ID)
+		}
+	}
+
+	if len(expiredIds) == 0 {
+		return
+	}
+
+	if err := manager.store.Delete(expiredIds...); err != nil {
+		log.Printf("Error removing expired jobs: %v", err)
+	}
+}
+
+func (manager *JobManager) run(job *dto.Job) {
+	if job.Batch != nil {
+		manager.runBatch(job)
+		return
+	}
+
+	manager.update(job, func(job *dto.Job) {
+		startedAt := time.Now()
+		job.Status = dto.JobRunning
+		job.StartedAt = &startedAt
+	})
+
+	progress := func(enricher dto.Enricher, observable dto.Observable, status dto.JobStatus) {
+		manager.modify(job, func(job *dto.Job) {
+			setEnricherProgress(job, enricher.Name, observable, status)
+		})
+	}
+
+	input := job.Input
+	input.JobID = job.ID
+
+	ctx := context.Background()
+
+	stopSaving := manager.saveEvery(job, progressSaveInterval)
+	results, graph, err := manager.executor.ExecuteGraph(ctx, input, progress)
+	stopSaving()
+	if err != nil {
+		log.Printf("Error executing job %s: %v", job.ID, err)
+	}
+
+	var deliveryErr error
+	if len(results) > 0 && job.Input.WebhookUri != "" {
+		deliveryCtx, cancel := manager.webhookContext(ctx)
+		defer cancel()
+
+		outcomes := common.ParallelExecute(deliveryCtx, job.Input.WebhookUri, results, SendEnrichmentResult, manager.webhookConcurrency)
+		deliveryErr = common.OutcomesErrors(outcomes)
+		if graph != nil {
+			if _, err := SendEnrichmentGraph(deliveryCtx, job.ID, *graph, job.Input.WebhookUri); err != nil {
+				deliveryErr = common.MergeErrors([]error{deliveryErr, err})
+			}
+		}
+		if deliveryErr != nil {
+			log.Printf("Error sending enriched results of job %s: %v", job.ID, deliveryErr)
+		}
+	}
+
+	if job.Input.File != nil {
+		if err := manager.files.Remove(job.Input.Data); err != nil {
+			log.Printf("Error removing uploaded file of job %s: %v", job.ID, err)
+		}
+	}
+
+	manager.update(job, func(job *dto.Job) {
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		job.Results = results
+		job.Graph = graph
+		job.Errors = append(common.SplitErrors(err), common.SplitErrors(deliveryErr)...)
+		if err != nil {
+			job.Status = dto.JobFailed
+		} else {
+			job.Status = executors.GetExecutionStatus(results)
+		}
+	})
+}
+
+// maskJob hides the values of the secret request args from the callers,
+// the stored job keeps them to be resumed.
+func (manager *JobManager) maskJob(job dto.Job) dto.Job {
+	job.Input.Args = manager.executor.MaskSecretArgs(job.Input.Args)
+	return job
+}
+
+// webhookContext limits the delivery of the results of a job, a receiver
+// which never answers would otherwise keep the job running.
+func (manager *JobManager) webhookContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if manager.webhookTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, manager.webhookTimeout)
+}
+
+func (manager *JobManager) update(job *dto.Job, mutate func(job *dto.Job)) dto.Job {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	mutate(job)
+	snapshot := copyJob(job)
+
+	if err := manager.store.Save(snapshot); err != nil {
+		log.Printf("Error saving job %s: %v", job.ID, err)
+	}
+
+	return snapshot
+}
+
+// modify changes the job without saving it.
+func (manager *JobManager) modify(job *dto.Job, mutate func(job *dto.Job)) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	mutate(job)
+}
+
+// saveEvery periodically saves the job until stop is called.
+func (manager *JobManager) saveEvery(job *dto.Job, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				manager.update(job, func(job *dto.Job) {})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func setEnricherProgress(job *dto.Job, enricherName string, observable dto.Observable, status dto.JobStatus) {
+	for i := range job.Progress {
+		progress := job.Progress[i]
+		if progress.Enricher == enricherName && progress.Observable == observable.Value && progress.DataType == observable.Type {
+			job.Progress[i].Status = status
+			return
+		}
+	}
+	job.Progress = append(job.Progress, dto.EnricherProgress{
+		Enricher:   enricherName,
+		Observable: observable.Value,
+		DataType:   observable.Type,
+		Status:     status,
+	})
+}
+
+func copyJob(job *dto.Job) dto.Job {
+	snapshot := *job
+	snapshot.Progress = append([]dto.EnricherProgress(nil), job.Progress...)
+	snapshot.Results = append([]dto.EnricherResultEnvelope(nil), job.Results...)
+	snapshot.Batch = copyBatch(job.Batch)
+	snapshot.Errors = append([]string(nil), job.Errors...)
+	return snapshot
+}
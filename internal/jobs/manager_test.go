@@ -0,0 +1,278 @@
+package jobs
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"enricher/internal/jobs/store"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingExecutor fails the execution of every job.
+type failingExecutor struct {
+	resultExecutor
+}
+
+func (executor failingExecutor) ExecuteGraph(ctx context.Context, input dto.EnricherInputData, progress executors.ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error) {
+	return nil, nil, errors.New("enrichers not available")
+}
+
+func TestJobLifecycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		executor    executors.EnricherExecutor
+		wantStatus  dto.JobStatus
+		wantResults int
+		wantErrors  []string
+	}{
+		{"succeeded", resultExecutor{}, dto.JobSucceeded, 1, nil},
+		{"failed", failingExecutor{}, dto.JobFailed, 0, []string{"enrichers not available"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewJobManager(tt.executor, store.NewInMemoryJobStore(), nil, configs.JobsConfig{})
+
+			submitted, err := manager.Submit(dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN})
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+			if submitted.ID == "" || submitted.Status != dto.JobPending {
+				t.Errorf("submitted job id = %q, status = %s, want an id and %s", submitted.ID, submitted.Status, dto.JobPending)
+			}
+
+			finished := waitForJob(t, manager, submitted.ID)
+			if finished.Status != tt.wantStatus {
+				t.Errorf("job status = %s, want %s", finished.Status, tt.wantStatus)
+			}
+			if finished.StartedAt == nil {
+				t.Error("job has no start time")
+			}
+			if len(finished.Results) != tt.wantResults {
+				t.Errorf("job results = %v, want %d", finished.Results, tt.wantResults)
+			}
+			if !reflect.DeepEqual(finished.Errors, tt.wantErrors) {
+				t.Errorf("job errors = %v, want %v", finished.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestGetUnknownJob(t *testing.T) {
+	manager := NewJobManager(resultExecutor{}, store.NewInMemoryJobStore(), nil, configs.JobsConfig{})
+
+	if _, err := manager.GetJob("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("GetJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+}
+
+// progressExecutor reports the progress of many enrichers.
+type progressExecutor struct {
+	resultExecutor
+	enrichers int
+}
+
+func (executor progressExecutor) ExecuteGraph(ctx context.Context, input dto.EnricherInputData, progress executors.ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error) {
+	observable := dto.Observable{Value: input.Data, Type: input.DataType}
+	for i := range executor.enrichers {
+		enricher := dto.Enricher{Name: string(rune('a' + i))}
+		progress(enricher, observable, dto.JobRunning)
+		progress(enricher, observable, dto.JobSucceeded)
+	}
+	return executor.resultExecutor.ExecuteGraph(ctx, input, progress)
+}
+
+// countingStore counts the saved jobs.
+type countingStore struct {
+	store.JobStore
+	saves atomic.Int32
+}
+
+func (store *countingStore) Save(job dto.Job) error {
+	store.saves.Add(1)
+	return store.JobStore.Save(job)
+}
+
+func TestJobProgressIsNotSavedPerEnricher(t *testing.T) {
+	tests := []struct {
+		name      string
+		enrichers int
+	}{
+		{"one enricher", 1},
+		{"many enrichers", 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &countingStore{JobStore: store.NewInMemoryJobStore()}
+			manager := NewJobManager(progressExecutor{enrichers: tt.enrichers}, jobStore, nil, configs.JobsConfig{})
+
+			job, err := manager.Submit(dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN})
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+
+			finished := waitForJob(t, manager, job.ID)
+			if len(finished.Progress) != tt.enrichers {
+				t.Errorf("job progress has %d enrichers, want %d", len(finished.Progress), tt.enrichers)
+			}
+			for _, progress := range finished.Progress {
+				if progress.Status != dto.JobSucceeded {
+					t.Errorf("enricher %s progress = %s, want %s", progress.Enricher, progress.Status, dto.JobSucceeded)
+				}
+			}
+			// submitted, started and finished
+			if saves := jobStore.saves.Load(); saves != 3 {
+				t.Errorf("job saved %d times, want 3", saves)
+			}
+		})
+	}
+}
+
+// argsExecutor records the args the job is executed with.
+type argsExecutor struct {
+	resultExecutor
+	executed chan map[string]map[string]any
+}
+
+func (executor argsExecutor) ExecuteGraph(ctx context.Context, input dto.EnricherInputData, progress executors.ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error) {
+	executor.executed <- input.Args
+	return executor.resultExecutor.ExecuteGraph(ctx, input, progress)
+}
+
+func TestJobSecretArgsAreMasked(t *testing.T) {
+	requestArgs := map[string]map[string]any{"whois": {"apiKey": "secret", "maxResults": 5}}
+	wantMasked := map[string]map[string]any{"whois": {"apiKey": dto.MaskedValue, "maxResults": 5}}
+
+	executor := argsExecutor{executed: make(chan map[string]map[string]any, 1)}
+	manager := NewJobManager(executor, store.NewInMemoryJobStore(), nil, configs.JobsConfig{})
+
+	submitted, err := manager.Submit(dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, Args: requestArgs})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	finished := waitForJob(t, manager, submitted.ID)
+
+	tests := []struct {
+		name string
+		args map[string]map[string]any
+		want map[string]map[string]any
+	}{
+		{"submitted job", submitted.Input.Args, wantMasked},
+		{"finished job", finished.Input.Args, wantMasked},
+		{"executed args", <-executor.executed, requestArgs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.args, tt.want) {
+				t.Errorf("args = %v, want %v", tt.args, tt.want)
+			}
+		})
+	}
+}
+
+// uploadedFiles tracks the uploads which exist and the removed ones.
+type uploadedFiles struct {
+	existing map[string]bool
+	mu       sync.Mutex
+	removed  []string
+}
+
+func (files *uploadedFiles) Exists(path string) bool {
+	return files.existing[path]
+}
+
+func (files *uploadedFiles) Remove(path string) error {
+	files.mu.Lock()
+	defer files.mu.Unlock()
+
+	files.removed = append(files.removed, path)
+	return nil
+}
+
+func (files *uploadedFiles) RemoveUnused(used []string) error {
+	return nil
+}
+
+func TestResumeUnfinishedJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	finishedAt := time.Now()
+	file := &dto.FileAttributes{Name: "sample.bin"}
+	storedJobs := []dto.Job{
+		{ID: "finished", Status: dto.JobSucceeded, FinishedAt: &finishedAt, Input: dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN}},
+		{ID: "running", Status: dto.JobRunning, StartedAt: &finishedAt, Input: dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN},
+			Progress: []dto.EnricherProgress{{Enricher: "echo", Status: dto.JobRunning}}},
+		{ID: "uploaded", Status: dto.JobPending, Input: dto.EnricherInputData{Data: "/uploads/present", DataType: dto.FILE, File: file}},
+		{ID: "missing-upload", Status: dto.JobRunning, Input: dto.EnricherInputData{Data: "/uploads/missing", DataType: dto.FILE, File: file}},
+	}
+
+	// the jobs were stored before the service restarted
+	jobStore, err := store.NewBoltJobStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltJobStore() error = %v", err)
+	}
+	for _, job := range storedJobs {
+		if err := jobStore.Save(job); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	jobStore.Close()
+
+	jobStore, err = store.NewBoltJobStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltJobStore() error = %v", err)
+	}
+	defer jobStore.Close()
+
+	files := &uploadedFiles{existing: map[string]bool{"/uploads/present": true}}
+	manager := NewJobManager(resultExecutor{}, jobStore, files, configs.JobsConfig{})
+	if err := manager.ResumeUnfinishedJobs(); err != nil {
+		t.Fatalf("ResumeUnfinishedJobs() error = %v", err)
+	}
+
+	tests := []struct {
+		id          string
+		wantStatus  dto.JobStatus
+		wantResults int
+		wantErrors  bool
+	}{
+		{"finished", dto.JobSucceeded, 0, false},
+		{"running", dto.JobSucceeded, 1, false},
+		{"uploaded", dto.JobSucceeded, 1, false},
+		{"missing-upload", dto.JobFailed, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			job := waitForJob(t, manager, tt.id)
+			if job.Status != tt.wantStatus {
+				t.Errorf("job status = %s, want %s", job.Status, tt.wantStatus)
+			}
+			if len(job.Results) != tt.wantResults {
+				t.Errorf("job results = %v, want %d", job.Results, tt.wantResults)
+			}
+			if (len(job.Errors) > 0) != tt.wantErrors {
+				t.Errorf("job errors = %v, want errors %v", job.Errors, tt.wantErrors)
+			}
+			for _, progress := range job.Progress {
+				if progress.Status == dto.JobRunning {
+					t.Errorf("stale progress of enricher %s kept", progress.Enricher)
+				}
+			}
+		})
+	}
+
+	files.mu.Lock()
+	defer files.mu.Unlock()
+	if !reflect.DeepEqual(files.removed, []string{"/uploads/present"}) {
+		t.Errorf("removed uploads = %v, want [/uploads/present]", files.removed)
+	}
+}
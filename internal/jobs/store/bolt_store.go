@@ -0,0 +1,94 @@
+package store
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"time"
+)
+
+var jobsBucket = []byte("jobs")
+
+type BoltJobStore struct {
+	db *bbolt.DB
+}
+
+func NewBoltJobStore(path string) (*BoltJobStore, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize job store %s: %w", path, err)
+	}
+
+	return &BoltJobStore{db: db}, nil
+}
+
+func (store *BoltJobStore) Save(job dto.Job) error {
+
+	value, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encoding job %s error: %w", job.ID, err)
+	}
+
+	return store.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(job.ID), value)
+	})
+}
+
+func (store *BoltJobStore) Get(id string) (dto.Job, error) {
+
+	var job dto.Job
+	err := store.db.View(func(tx *bbolt.Tx) error {
+		value := tx.Bucket(jobsBucket).Get([]byte(id))
+		if value == nil {
+			return ErrJobNotFound
+		}
+		return json.Unmarshal(value, &job)
+	})
+
+	return job, err
+}
+
+func (store *BoltJobStore) List() ([]dto.Job, error) {
+
+	var jobs []dto.Job
+	err := store.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(key, value []byte) error {
+			var job dto.Job
+			if err := json.Unmarshal(value, &job); err != nil {
+				return fmt.Errorf("decoding job %s error: %w", key, err)
+			}
+			jobs = append(jobs, job)
+			return nil
+		})
+	})
+
+	return jobs, err
+}
+
+func (store *BoltJobStore) Delete(ids ...string) error {
+
+	return store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(jobsBucket)
+		for _, id := range ids {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *BoltJobStore) Close() error {
+
+	return store.db.Close()
+}
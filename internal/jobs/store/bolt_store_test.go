@@ -0,0 +1,81 @@
+package store
+
+import (
+	"enricher/internal/enricher/dto"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func listIds(t *testing.T, store JobStore) []string {
+	t.Helper()
+
+	jobs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestBoltJobStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := dto.Job{
+		ID:        "job-1",
+		Status:    dto.JobRunning,
+		Input:     dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, Args: map[string]map[string]any{"whois": {"apiKey": "secret"}}},
+		CreatedAt: createdAt,
+		StartedAt: &createdAt,
+		Progress:  []dto.EnricherProgress{{Enricher: "whois", Status: dto.JobRunning}},
+	}
+
+	store, err := NewBoltJobStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltJobStore() error = %v", err)
+	}
+	for _, id := range []string{"job-1", "job-2", "job-3"} {
+		saved := job
+		saved.ID = id
+		if err := store.Save(saved); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	job.Status = dto.JobSucceeded
+	if err := store.Save(job); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Delete("job-2", "unknown"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := NewBoltJobStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltJobStore() error = %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.Get("job-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, job) {
+		t.Errorf("Get() = %+v, want %+v", got, job)
+	}
+	if _, err := reopened.Get("job-2"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Get() deleted job error = %v, want %v", err, ErrJobNotFound)
+	}
+	if ids := listIds(t, reopened); !reflect.DeepEqual(ids, []string{"job-1", "job-3"}) {
+		t.Errorf("List() = %v, want [job-1 job-3]", ids)
+	}
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"enricher/internal/enricher/dto"
+	"sync"
+)
+
+type InMemoryJobStore struct {
+	jobs map[string]dto.Job
+	mu   sync.RWMutex
+}
+
+func NewInMemoryJobStore() *InMemoryJobStore {
+
+	return &InMemoryJobStore{
+		jobs: make(map[string]dto.Job),
+	}
+}
+
+func (store *InMemoryJobStore) Save(job dto.Job) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.jobs[job.ID] = job
+
+	return nil
+}
+
+func (store *InMemoryJobStore) Get(id string) (dto.Job, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	job, exists := store.jobs[id]
+	if !exists {
+		return dto.Job{}, ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+func (store *InMemoryJobStore) List() ([]dto.Job, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	jobs := make([]dto.Job, 0, len(store.jobs))
+	for _, job := range store.jobs {
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (store *InMemoryJobStore) Delete(ids ...string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, id := range ids {
+		delete(store.jobs, id)
+	}
+
+	return nil
+}
+
+func (store *InMemoryJobStore) Close() error {
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"enricher/internal/enricher/dto"
+	"errors"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+type JobStore interface {
+	Save(job dto.Job) error
+	Get(id string) (dto.Job, error)
+	List() ([]dto.Job, error)
+	Delete(ids ...string) error
+	Close() error
+}
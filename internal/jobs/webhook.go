@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ErrWebhookTimeout is returned when the receiver does not answer before
+// the delivery deadline.
+var ErrWebhookTimeout = errors.New("webhook delivery timed out")
+
+func SendEnrichmentResult(ctx context.Context, enrichmentResult dto.EnricherResultEnvelope, url string) (bool, error) {
+
+	return sendWebhook(ctx, enrichmentResult, url)
+}
+
+// SendEnrichmentGraph is sent once all the results of a pivoting job
+// were delivered.
+func SendEnrichmentGraph(ctx context.Context, jobID string, graph dto.EnrichmentGraph, url string) (bool, error) {
+
+	return sendWebhook(ctx, dto.WebhookNotification{Kind: dto.GraphWebhook, JobID: jobID, Graph: &graph}, url)
+}
+
+// SendBatchSummary is sent once the results of all the observables of a
+// batch were delivered.
+func SendBatchSummary(ctx context.Context, summary dto.BatchSummary, url string) (bool, error) {
+
+	return sendWebhook(ctx, dto.WebhookNotification{Kind: dto.BatchSummaryWebhook, JobID: summary.JobID, Batch: &summary}, url)
+}
+
+func sendWebhook(ctx context.Context, payload any, url string) (bool, error) {
+
+	resultJson, err := json.Marshal(payload)
+
+	if err != nil {
+		log.Printf("Error marshalling enrichment result: %v", err)
+		return false, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(resultJson))
+
+	if err != nil {
+		log.Printf("Error creating webhook request: %v", err)
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %v", ErrWebhookTimeout, err)
+	}
+	if err != nil {
+		log.Printf("Error sending enriched result: %v", err)
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errorMessage := fmt.Sprintf("Error sending enriched result: %v", resp.Status)
+		log.Print(errorMessage)
+		return false, errors.New(errorMessage)
+	}
+
+	return true, nil
+}
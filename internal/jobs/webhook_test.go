@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"enricher/internal/jobs/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resultExecutor returns one succeeded result for every job, the apiKey
+// args are secret.
+type resultExecutor struct {
+	executors.EnricherExecutor
+}
+
+func (executor resultExecutor) MaskSecretArgs(requestArgs map[string]map[string]any) map[string]map[string]any {
+	masked := make(map[string]map[string]any, len(requestArgs))
+	for enricherName, values := range requestArgs {
+		masked[enricherName] = make(map[string]any, len(values))
+		for name, value := range values {
+			if name == "apiKey" {
+				value = dto.MaskedValue
+			}
+			masked[enricherName][name] = value
+		}
+	}
+	return masked
+}
+
+func (executor resultExecutor) SelectEnrichers(input dto.EnricherInputData) ([]dto.Enricher, error) {
+	return []dto.Enricher{{Name: "echo"}}, nil
+}
+
+func (executor resultExecutor) ExecuteGraph(ctx context.Context, input dto.EnricherInputData, progress executors.ProgressFunc) ([]dto.EnricherResultEnvelope, *dto.EnrichmentGraph, error) {
+	return []dto.EnricherResultEnvelope{{Enricher: "echo", Observable: input.Data, DataType: input.DataType, Status: dto.JobSucceeded}}, nil, nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantErrors string
+	}{
+		{
+			name:    "delivered",
+			handler: func(response http.ResponseWriter, request *http.Request) {},
+		},
+		{
+			name: "rejected by the receiver",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				response.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantErrors: "503 Service Unavailable",
+		},
+		{
+			name: "receiver never answers",
+			handler: func(response http.ResponseWriter, request *http.Request) {
+				select {
+				case <-request.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			wantErrors: ErrWebhookTimeout.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			manager := NewJobManager(resultExecutor{}, store.NewInMemoryJobStore(), nil, configs.JobsConfig{WebhookConcurrency: 1})
+			manager.webhookTimeout = 100 * time.Millisecond
+
+			job, err := manager.Submit(dto.EnricherInputData{Data: "example.com", DataType: dto.DOMAIN, WebhookUri: server.URL})
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+
+			finished := waitForJob(t, manager, job.ID)
+			gotErrors := strings.Join(finished.Errors, "\n")
+			if tt.wantErrors == "" && gotErrors != "" || !strings.Contains(gotErrors, tt.wantErrors) {
+				t.Errorf("job errors = %q, want %q", gotErrors, tt.wantErrors)
+			}
+			if finished.Status != dto.JobSucceeded {
+				t.Errorf("job status = %s, want %s", finished.Status, dto.JobSucceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/executors"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+func EnrichersLoadReport(response http.ResponseWriter, request *http.Request, registry enricher.EnricherRegistry) {
+
+	writeJSON(response, http.StatusOK, registry.LoadReport())
+}
+
+func ReloadEnrichers(response http.ResponseWriter, request *http.Request, registry enricher.EnricherRegistry) {
+
+	report, err := registry.ReloadEnrichers()
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusUnprocessableEntity)
+		log.Printf("Error while enrichers reload: %v", err)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, report)
+}
+
+func SetEnricherEnabled(response http.ResponseWriter, request *http.Request, registry enricher.EnricherRegistry, catalog executors.EnricherCatalog, enabled bool) {
+
+	updateEnricher(response, request, registry, catalog, dto.EnricherOverride{Enabled: &enabled})
+}
+
+func UpdateEnricher(response http.ResponseWriter, request *http.Request, registry enricher.EnricherRegistry, catalog executors.EnricherCatalog) {
+
+	body, err := io.ReadAll(request.Body)
+
+	if err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading request body: %v", err)
+		return
+	}
+	defer request.Body.Close()
+
+	var update dto.EnricherOverride
+
+	if err := json.Unmarshal(body, &update); err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error unmarshalling request body: %v", err)
+		return
+	}
+
+	updateEnricher(response, request, registry, catalog, update)
+}
+
+func updateEnricher(response http.ResponseWriter, request *http.Request, registry enricher.EnricherRegistry, catalog executors.EnricherCatalog, update dto.EnricherOverride) {
+
+	name := request.PathValue("name")
+	err := registry.UpdateEnricher(name, update)
+
+	switch {
+	case errors.Is(err, enricher.ErrEnricherNotFound):
+		http.Error(response, "Enricher not found", http.StatusNotFound)
+		return
+	case errors.Is(err, enricher.ErrInvalidOverride):
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(response, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error updating enricher %s: %v", name, err)
+		return
+	}
+
+	enricherInfo, err := catalog.GetEnricherInfo(name)
+
+	if err != nil {
+		http.Error(response, "Enricher not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, enricherInfo)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"enricher/internal/jobs"
+	"enricher/internal/server/middlewares"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// EnrichmentBatch submits a single job for all the observables. Inputs
+// which are invalid or of an ambiguous type are rejected without failing
+// the whole batch.
+func EnrichmentBatch(response http.ResponseWriter, request *http.Request, registry jobs.JobRegistry, maxBatchSize int) {
+
+	body, err := io.ReadAll(request.Body)
+
+	if err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading request body: %v", err)
+		return
+	}
+	defer request.Body.Close()
+
+	var input dto.BatchInputData
+	if err := json.Unmarshal(body, &input); err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error unmarshalling request body: %v", err)
+		return
+	}
+
+	if len(input.Observables) == 0 {
+		http.Error(response, "Observables are required", http.StatusBadRequest)
+		return
+	}
+	if maxBatchSize > 0 && len(input.Observables) > maxBatchSize {
+		http.Error(response, fmt.Sprintf("Batch exceeds %d observables", maxBatchSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	input.Data = ""
+	input.DataType = ""
+	input.File = nil
+	if input.Profile == "" {
+		input.Profile = middlewares.ProfileFromContext(request.Context())
+	}
+
+	var rejected []dto.BatchRejection
+	batchObservables := make([]dto.BatchObservable, 0, len(input.Observables))
+
+	for _, observable := range input.Observables {
+		dataType, value, err := resolveBatchObservable(observable)
+		if err != nil {
+			rejected = append(rejected, dto.BatchRejection{
+				Observable: observable.Data,
+				DataType:   observable.DataType,
+				Error:      err.Error(),
+			})
+			continue
+		}
+		batchObservables = append(batchObservables, dto.BatchObservable{Data: value, DataType: dataType})
+	}
+	input.Observables = batchObservables
+
+	job, err := registry.SubmitBatch(input, rejected)
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		log.Printf("Error submitting batch enrichment job: %v", err)
+		return
+	}
+	log.Printf("Batch enrichment job %s submitted: %d observables, %d rejected", job.ID, job.Batch.Unique, len(job.Batch.Rejected))
+
+	writeJSON(response, http.StatusAccepted, job)
+}
+
+// resolveBatchObservable detects the type when needed and normalizes the
+// value. Files have to be uploaded one by one to the enrichment endpoint.
+func resolveBatchObservable(observable dto.BatchObservable) (dto.EnricherArgType, string, error) {
+	dataType := observable.DataType
+
+	if dataType == "" || dataType == dto.AutoDetectType {
+		detection := observables.Detect(observable.Data)
+
+		if detection.Ambiguous {
+			candidates := make([]string, 0, len(detection.Candidates))
+			for _, candidate := range detection.Candidates {
+				candidates = append(candidates, string(candidate.Type))
+			}
+			return "", "", fmt.Errorf("ambiguous observable type, candidates: %s", strings.Join(candidates, ", "))
+		}
+		dataType = detection.Type
+	}
+
+	if dataType == dto.FILE {
+		return "", "", errFileNotUploaded
+	}
+
+	value, err := observables.Normalize(dataType, observable.Data)
+	return dataType, value, err
+}
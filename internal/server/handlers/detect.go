@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/enricher/observables"
+	"io"
+	"log"
+	"net/http"
+)
+
+func Detect(response http.ResponseWriter, request *http.Request) {
+
+	body, err := io.ReadAll(request.Body)
+
+	if err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading request body: %v", err)
+		return
+	}
+	defer request.Body.Close()
+
+	var detectionRequest dto.DetectionRequest
+
+	if err := json.Unmarshal(body, &detectionRequest); err != nil {
+		http.Error(response, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error unmarshalling request body: %v", err)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, observables.Detect(detectionRequest.Data))
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"enricher/internal/enricher/executors"
+	"errors"
+	"net/http"
+)
+
+func Enrichers(response http.ResponseWriter, request *http.Request, catalog executors.EnricherCatalog) {
+
+	writeJSON(response, http.StatusOK, catalog.ListEnrichers())
+}
+
+func Enricher(response http.ResponseWriter, request *http.Request, catalog executors.EnricherCatalog) {
+
+	enricherInfo, err := catalog.GetEnricherInfo(request.PathValue("name"))
+
+	if errors.Is(err, executors.ErrEnricherNotFound) {
+		http.Error(response, "Enricher not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, enricherInfo)
+}
@@ -1,81 +1,239 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"enricher/internal/common"
 	"enricher/internal/enricher/dto"
 	"enricher/internal/enricher/executors"
+	"enricher/internal/enricher/observables"
+	"enricher/internal/jobs"
+	"enricher/internal/server/middlewares"
+	"enricher/internal/uploads"
 	"errors"
-	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 )
 
-func SendEnrichmentResult(enrichmentResult dto.EnricherResult, url string) (bool, error) {
+// multipartOverhead is accepted on top of the upload size limit for the
+// request part and the multipart boundaries.
+const multipartOverhead = 1 << 20
 
-	resultJson, err := json.Marshal(enrichmentResult)
+// errFileNotUploaded keeps clients from naming arbitrary server paths as
+// FILE observables, the plugins open the path they are given.
+var errFileNotUploaded = errors.New("FILE observables must be uploaded as multipart/form-data")
+
+func readEnrichmentRequest(response http.ResponseWriter, request *http.Request, fileStore *uploads.FileStore) (dto.EnricherInputData, bool) {
+
+	if request.Method != http.MethodPost {
+		http.Error(response, "Method not allowed", http.StatusMethodNotAllowed)
+		return dto.EnricherInputData{}, false
+	}
+
+	var inputEnricher dto.EnricherInputData
+	var err error
+
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		inputEnricher, err = readMultipartEnrichmentRequest(response, request, fileStore)
+
+		if errors.Is(err, uploads.ErrFileTooLarge) {
+			http.Error(response, err.Error(), http.StatusRequestEntityTooLarge)
+			log.Printf("Error reading uploaded file: %v", err)
+			return dto.EnricherInputData{}, false
+		}
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusBadRequest)
+			log.Printf("Error reading multipart request: %v", err)
+			return dto.EnricherInputData{}, false
+		}
+	} else {
+		body, err := io.ReadAll(request.Body)
+
+		if err != nil {
+			http.Error(response, "Invalid request body", http.StatusBadRequest)
+			log.Printf("Error reading request body: %v", err)
+			return dto.EnricherInputData{}, false
+		}
+		defer request.Body.Close()
+
+		if err := json.Unmarshal(body, &inputEnricher); err != nil {
+			http.Error(response, "Invalid request body", http.StatusBadRequest)
+			log.Printf("Error unmarshalling request body: %v", err)
+			return dto.EnricherInputData{}, false
+		}
+		inputEnricher.File = nil
+	}
+
+	if inputEnricher.DataType == "" || inputEnricher.DataType == dto.AutoDetectType {
+		detection := observables.Detect(inputEnricher.Data)
+
+		if detection.Ambiguous {
+			writeJSON(response, http.StatusBadRequest, detection)
+			log.Printf("Observable type detection is ambiguous: %d candidates", len(detection.Candidates))
+			return dto.EnricherInputData{}, false
+		}
+		log.Printf("Observable type detected: %s (%.2f)", detection.Type, detection.Confidence)
+		inputEnricher.DataType = detection.Type
+	}
+
+	inputEnricher.Data, err = observables.Normalize(inputEnricher.DataType, inputEnricher.Data)
 
 	if err != nil {
-		log.Printf("Error marshalling enrichment result: %v", err)
-		return false, err
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		log.Printf("Error validating observable: %v", err)
+		return dto.EnricherInputData{}, false
+	}
+
+	if inputEnricher.DataType == dto.FILE && inputEnricher.File == nil {
+		http.Error(response, errFileNotUploaded.Error(), http.StatusBadRequest)
+		log.Printf("Error validating observable: %v", errFileNotUploaded)
+		return dto.EnricherInputData{}, false
+	}
+
+	if inputEnricher.Profile == "" {
+		inputEnricher.Profile = middlewares.ProfileFromContext(request.Context())
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(resultJson))
+	return inputEnricher, true
+}
+
+// readMultipartEnrichmentRequest reads a FILE enrichment request. The
+// upload is the "file" part, the optional "request" part holds the other
+// request fields as JSON.
+func readMultipartEnrichmentRequest(response http.ResponseWriter, request *http.Request, fileStore *uploads.FileStore) (dto.EnricherInputData, error) {
 
+	request.Body = http.MaxBytesReader(response, request.Body, fileStore.MaxSize()+multipartOverhead)
+	defer request.Body.Close()
+
+	reader, err := request.MultipartReader()
 	if err != nil {
-		log.Printf("Error sending enriched result: %v", err)
-		return false, err
+		return dto.EnricherInputData{}, err
+	}
+
+	var inputEnricher dto.EnricherInputData
+	var path string
+	var file dto.FileAttributes
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			switch part.FormName() {
+			case "request":
+				err = json.NewDecoder(io.LimitReader(part, multipartOverhead)).Decode(&inputEnricher)
+			case "file":
+				if path != "" {
+					err = errors.New("only one file part is allowed")
+				} else {
+					path, file, err = fileStore.Save(part, part.FileName())
+				}
+			}
+			part.Close()
+		}
+
+		if err != nil {
+			if path != "" {
+				fileStore.Remove(path)
+			}
+			return dto.EnricherInputData{}, err
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		errorMessage := fmt.Sprintf("Error sending enriched result: %v", resp.Status)
-		log.Printf(errorMessage)
-		return false, errors.New(errorMessage)
+	if path == "" {
+		return dto.EnricherInputData{}, errors.New("file part is required")
 	}
 
-	return true, nil
+	inputEnricher.Data = path
+	inputEnricher.DataType = dto.FILE
+	inputEnricher.File = &file
+
+	return inputEnricher, nil
+}
+
+func removeUploadedFile(fileStore *uploads.FileStore, inputEnricher dto.EnricherInputData) {
+	if inputEnricher.File == nil {
+		return
+	}
+	if err := fileStore.Remove(inputEnricher.Data); err != nil {
+		log.Printf("Error removing uploaded file: %v", err)
+	}
 }
 
-func Enrichment(response http.ResponseWriter, request *http.Request, executor executors.EnricherExecutor) {
+func writeJSON(response http.ResponseWriter, status int, value any) {
 
-	if request.Method != http.MethodPost {
-		http.Error(response, "Method not allowed", http.StatusMethodNotAllowed)
+	body, err := json.Marshal(value)
+
+	if err != nil {
+		http.Error(response, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error marshalling response: %v", err)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	response.Write(body)
+}
+
+func Enrichment(response http.ResponseWriter, request *http.Request, registry jobs.JobRegistry, fileStore *uploads.FileStore) {
+
+	inputEnricher, ok := readEnrichmentRequest(response, request, fileStore)
+	if !ok {
 		return
 	}
 
-	body, err := io.ReadAll(request.Body)
+	job, err := registry.Submit(inputEnricher)
 
 	if err != nil {
-		http.Error(response, "Invalid request body", http.StatusBadRequest)
-		log.Printf("Error reading request body: %v", err)
+		removeUploadedFile(fileStore, inputEnricher)
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		log.Printf("Error submitting enrichment job: %v", err)
 		return
 	}
-	defer request.Body.Close()
+	log.Printf("Enrichment job %s submitted", job.ID)
 
-	var inputEnricher dto.EnricherInputData
+	writeJSON(response, http.StatusAccepted, job)
+}
 
-	if err := json.Unmarshal(body, &inputEnricher); err != nil {
-		http.Error(response, "Invalid request body", http.StatusBadRequest)
-		log.Printf("Error unmarshalling request body: %v", err)
+func EnrichmentSync(response http.ResponseWriter, request *http.Request, executor executors.EnricherExecutor, fileStore *uploads.FileStore, timeout time.Duration) {
+
+	inputEnricher, ok := readEnrichmentRequest(response, request, fileStore)
+	if !ok {
 		return
 	}
+	defer removeUploadedFile(fileStore, inputEnricher)
 
-	go func() {
-		results, err := executor.ExecuteEnrichers(inputEnricher)
-		if err != nil {
-			log.Printf("Error executing enricher: %v", err)
-			return
-		}
+	if _, err := executor.SelectEnrichers(inputEnricher); err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		log.Printf("Error selecting enrichers: %v", err)
+		return
+	}
 
-		_, err = common.ParallelExecute(inputEnricher.WebhookUri, results, SendEnrichmentResult)
-		if err != nil {
-			log.Printf("Error sending enriched results: %v", err)
-			return
-		}
-	}()
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+
+	results, graph, err := executor.ExecuteGraph(ctx, inputEnricher, nil)
+
+	status := executors.GetExecutionStatus(results)
+	if err != nil {
+		log.Printf("Error executing enricher: %v", err)
+		status = dto.JobFailed
+	}
+
+	httpStatus := http.StatusOK
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("Synchronous enrichment of %s interrupted: %v", inputEnricher.DataType, ctx.Err())
+		httpStatus = http.StatusGatewayTimeout
+	}
 
-	response.WriteHeader(http.StatusAccepted)
-	response.Write([]byte("Enrichment process started"))
+	writeJSON(response, httpStatus, dto.EnrichmentResponse{
+		Status:  status,
+		Results: results,
+		Graph:   graph,
+		Errors:  common.SplitErrors(err),
+	})
 }
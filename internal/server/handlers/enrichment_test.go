@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"enricher/configs"
+	"enricher/internal/enricher/dto"
+	"enricher/internal/uploads"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+type multipartPart struct {
+	name     string
+	fileName string
+	content  string
+}
+
+func newMultipartRequest(t *testing.T, parts []multipartPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range parts {
+		var partWriter io.Writer
+		var err error
+		if part.fileName != "" {
+			partWriter, err = writer.CreateFormFile(part.name, part.fileName)
+		} else {
+			partWriter, err = writer.CreateFormField(part.name)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		partWriter.Write([]byte(part.content))
+	}
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/enrichment", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestReadMultipartEnrichmentRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		parts       []multipartPart
+		wantStatus  int
+		wantProfile string
+		wantSize    int64
+	}{
+		{
+			name: "file with request",
+			parts: []multipartPart{
+				{name: "request", content: `{"profile": "malware"}`},
+				{name: "file", fileName: "sample.exe", content: "MZ"},
+			},
+			wantStatus:  http.StatusOK,
+			wantProfile: "malware",
+			wantSize:    2,
+		},
+		{
+			name: "file at the size limit",
+			parts: []multipartPart{
+				{name: "file", fileName: "sample.bin", content: strings.Repeat("a", 64)},
+			},
+			wantStatus: http.StatusOK,
+			wantSize:   64,
+		},
+		{
+			name: "file above the size limit",
+			parts: []multipartPart{
+				{name: "file", fileName: "sample.bin", content: strings.Repeat("a", 65)},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "request above the size limit",
+			parts: []multipartPart{
+				{name: "request", content: `{"profile": "` + strings.Repeat("a", multipartOverhead) + `"}`},
+				{name: "file", fileName: "sample.bin", content: "a"},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "missing file",
+			parts: []multipartPart{
+				{name: "request", content: `{"profile": "malware"}`},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "two files",
+			parts: []multipartPart{
+				{name: "file", fileName: "first.bin", content: "a"},
+				{name: "file", fileName: "second.bin", content: "b"},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid request",
+			parts: []multipartPart{
+				{name: "request", content: `{"profile": `},
+				{name: "file", fileName: "sample.bin", content: "a"},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fileStore, err := uploads.NewFileStore(configs.UploadsConfig{Dir: dir, MaxSize: 64})
+			if err != nil {
+				t.Fatalf("NewFileStore() error = %v", err)
+			}
+
+			response := httptest.NewRecorder()
+			input, ok := readEnrichmentRequest(response, newMultipartRequest(t, tt.parts), fileStore)
+
+			if ok != (tt.wantStatus == http.StatusOK) || response.Code != tt.wantStatus {
+				t.Fatalf("readEnrichmentRequest() ok = %v, status = %d, want %d: %s", ok, response.Code, tt.wantStatus, response.Body)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			if !ok {
+				if len(entries) != 0 {
+					t.Errorf("readEnrichmentRequest() left %d uploaded files", len(entries))
+				}
+				return
+			}
+
+			if input.DataType != dto.FILE || input.File == nil || input.File.Size != tt.wantSize || input.Profile != tt.wantProfile {
+				t.Errorf("readEnrichmentRequest() = %+v", input)
+			}
+			if !fileStore.Exists(input.Data) {
+				t.Errorf("uploaded file %s does not exist", input.Data)
+			}
+		})
+	}
+}
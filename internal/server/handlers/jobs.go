@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"enricher/internal/enricher/dto"
+	"enricher/internal/jobs"
+	"errors"
+	"log"
+	"net/http"
+)
+
+func Job(response http.ResponseWriter, request *http.Request, registry jobs.JobRegistry) {
+
+	job, err := registry.GetJob(request.PathValue("id"))
+
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		http.Error(response, "Job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(response, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting job: %v", err)
+		return
+	}
+
+	job.Results = nil
+	writeJSON(response, http.StatusOK, job)
+}
+
+func JobResults(response http.ResponseWriter, request *http.Request, registry jobs.JobRegistry) {
+
+	job, err := registry.GetJob(request.PathValue("id"))
+
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		http.Error(response, "Job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(response, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting job results: %v", err)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, dto.JobResults{
+		ID:      job.ID,
+		Status:  job.Status,
+		Results: job.Results,
+		Graph:   job.Graph,
+		Batch:   job.Batch,
+		Errors:  job.Errors,
+	})
+}
@@ -1,22 +1,46 @@
 package middlewares
 
 import (
+	"context"
 	"enricher/configs"
 	"log"
 	"net/http"
 )
 
+type contextKey string
+
+const profileContextKey contextKey = "profile"
+
+// ProfileFromContext returns the default profile of the API key which
+// authorized the request.
+func ProfileFromContext(ctx context.Context) string {
+	profile, _ := ctx.Value(profileContextKey).(string)
+	return profile
+}
+
 func AuthMiddleware(next http.Handler, apiConfig configs.APIConfig) http.Handler {
 
-	keys := make(map[string]string)
+	return keyMiddleware(next, apiConfig.Keys)
+}
+
+// AdminMiddleware accepts only the admin keys, ordinary API keys are
+// rejected.
+func AdminMiddleware(next http.Handler, apiConfig configs.APIConfig) http.Handler {
 
-	for _, key := range apiConfig.Keys {
-		keys[key.Key] = key.Name
+	return keyMiddleware(next, apiConfig.AdminKeys)
+}
+
+func keyMiddleware(next http.Handler, apiKeys []configs.APIKey) http.Handler {
+
+	keys := make(map[string]configs.APIKey)
+
+	for _, key := range apiKeys {
+		keys[key.Key] = key
 	}
 
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		key := request.Header.Get("Authorization")
-		keyName, exists := keys[key]
+		apiKey, exists := keys[key]
 
 		if !exists {
 			log.Printf("Invalid API key: %s", key)
@@ -24,7 +48,11 @@ func AuthMiddleware(next http.Handler, apiConfig configs.APIConfig) http.Handler
 			return
 		}
 
-		log.Printf("API key '%s' have access to %s", keyName, request.URL.Path)
+		log.Printf("API key '%s' have access to %s", apiKey.Name, request.URL.Path)
+
+		if apiKey.Profile != "" {
+			request = request.WithContext(context.WithValue(request.Context(), profileContextKey, apiKey.Profile))
+		}
 
 		next.ServeHTTP(writer, request)
 	})
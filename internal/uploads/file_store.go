@@ -0,0 +1,144 @@
+package uploads
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"enricher/configs"
+	"enricher/internal/common"
+	"enricher/internal/enricher/dto"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const sniffLength = 512
+
+var ErrFileTooLarge = errors.New("file too large")
+
+// FileStore keeps the uploaded files in a managed directory until the
+// enrichment using them finishes.
+type FileStore struct {
+	dir     string
+	maxSize int64
+}
+
+func NewFileStore(config configs.UploadsConfig) (*FileStore, error) {
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "enricher-uploads")
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uploads directory %s: %w", config.Dir, err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create uploads directory %s: %w", dir, err)
+	}
+
+	return &FileStore{dir: dir, maxSize: config.MaxSize}, nil
+}
+
+func (store *FileStore) MaxSize() int64 {
+	return store.maxSize
+}
+
+// Save writes the upload to a temporary file while computing its hashes
+// and sniffing its MIME type.
+func (store *FileStore) Save(reader io.Reader, name string) (string, dto.FileAttributes, error) {
+	file, err := os.CreateTemp(store.dir, "upload-*")
+	if err != nil {
+		return "", dto.FileAttributes{}, fmt.Errorf("create upload file error: %w", err)
+	}
+	defer file.Close()
+
+	md5Hash, sha1Hash, sha256Hash := md5.New(), sha1.New(), sha256.New()
+	sniffer := &sniffWriter{}
+	writer := io.MultiWriter(file, md5Hash, sha1Hash, sha256Hash, sniffer)
+
+	size, err := io.Copy(writer, io.LimitReader(reader, store.maxSize+1))
+	if err == nil && size > store.maxSize {
+		err = fmt.Errorf("%w: limit is %d bytes", ErrFileTooLarge, store.maxSize)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", dto.FileAttributes{}, err
+	}
+
+	attributes := dto.FileAttributes{
+		Name:     filepath.Base(name),
+		Size:     size,
+		MD5:      hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA1:     hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256:   hex.EncodeToString(sha256Hash.Sum(nil)),
+		MimeType: http.DetectContentType(sniffer.data),
+	}
+
+	return file.Name(), attributes, nil
+}
+
+// Remove deletes an uploaded file, paths outside of the uploads directory
+// are never touched.
+func (store *FileStore) Remove(path string) error {
+	if !store.isUpload(path) {
+		return fmt.Errorf("not an uploaded file: %s", path)
+	}
+
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Exists tells whether the uploaded file is still in the uploads directory.
+func (store *FileStore) Exists(path string) bool {
+	if !store.isUpload(path) {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// RemoveUnused deletes the uploaded files which are not in use, they are
+// left over by a crash or a restart of the service.
+func (store *FileStore) RemoveUnused(used []string) error {
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		path := filepath.Join(store.dir, entry.Name())
+		if entry.IsDir() || !store.isUpload(path) || slices.Contains(used, path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return common.MergeErrors(errs)
+}
+
+func (store *FileStore) isUpload(path string) bool {
+	return filepath.Dir(path) == store.dir && strings.HasPrefix(filepath.Base(path), "upload-")
+}
+
+type sniffWriter struct {
+	data []byte
+}
+
+func (writer *sniffWriter) Write(data []byte) (int, error) {
+	if remaining := sniffLength - len(writer.data); remaining > 0 {
+		writer.data = append(writer.data, data[:min(remaining, len(data))]...)
+	}
+	return len(data), nil
+}
@@ -0,0 +1,192 @@
+package uploads
+
+import (
+	"enricher/configs"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestFileStore(t *testing.T, maxSize int64) *FileStore {
+	t.Helper()
+
+	store, err := NewFileStore(configs.UploadsConfig{Dir: t.TempDir(), MaxSize: maxSize})
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	return store
+}
+
+func TestFileStoreSave(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		fileName     string
+		maxSize      int64
+		wantErr      error
+		wantName     string
+		wantMD5      string
+		wantSHA256   string
+		wantMimeType string
+	}{
+		{
+			name:         "empty file",
+			content:      "",
+			fileName:     "empty.bin",
+			maxSize:      10,
+			wantName:     "empty.bin",
+			wantMD5:      "d41d8cd98f00b204e9800998ecf8427e",
+			wantSHA256:   "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			wantMimeType: "text/plain; charset=utf-8",
+		},
+		{
+			name:         "text at the size limit",
+			content:      "hello",
+			fileName:     "hello.txt",
+			maxSize:      5,
+			wantName:     "hello.txt",
+			wantMD5:      "5d41402abc4b2a76b9719d911017c592",
+			wantSHA256:   "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			wantMimeType: "text/plain; charset=utf-8",
+		},
+		{
+			name:         "directories are dropped from the name",
+			content:      "%PDF-1.4\n",
+			fileName:     "../../etc/report.pdf",
+			maxSize:      1024,
+			wantName:     "report.pdf",
+			wantMD5:      "6446a98080f5e51ab7f0abc0e8eda635",
+			wantSHA256:   "e5c62df5dab5c87b6a015ef3d43597074d1eec433b15f51aec63b8582d0e4ab4",
+			wantMimeType: "application/pdf",
+		},
+		{
+			name:     "one byte above the limit",
+			content:  "hello!",
+			fileName: "hello.txt",
+			maxSize:  5,
+			wantErr:  ErrFileTooLarge,
+		},
+		{
+			name:     "far above the limit",
+			content:  strings.Repeat("a", 4096),
+			fileName: "big.txt",
+			maxSize:  1024,
+			wantErr:  ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestFileStore(t, tt.maxSize)
+
+			path, attributes, err := store.Save(strings.NewReader(tt.content), tt.fileName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Save() error = %v, want %v", err, tt.wantErr)
+			}
+
+			entries, _ := os.ReadDir(store.dir)
+			if err != nil {
+				if len(entries) != 0 {
+					t.Errorf("Save() left %d files after the error", len(entries))
+				}
+				return
+			}
+
+			saved, err := os.ReadFile(path)
+			if err != nil || string(saved) != tt.content {
+				t.Errorf("saved content = %q (%v), want %q", saved, err, tt.content)
+			}
+			if !store.Exists(path) {
+				t.Errorf("Exists(%s) = false after Save()", path)
+			}
+			if attributes.Name != tt.wantName || attributes.Size != int64(len(tt.content)) || attributes.MimeType != tt.wantMimeType {
+				t.Errorf("Save() attributes = %+v", attributes)
+			}
+			if attributes.MD5 != tt.wantMD5 || attributes.SHA256 != tt.wantSHA256 {
+				t.Errorf("Save() hashes = %s %s, want %s %s", attributes.MD5, attributes.SHA256, tt.wantMD5, tt.wantSHA256)
+			}
+		})
+	}
+}
+
+func TestFileStoreRemove(t *testing.T) {
+	store := newTestFileStore(t, 1024)
+
+	upload, _, err := store.Save(strings.NewReader("data"), "data.txt")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	other := filepath.Join(store.dir, "other.txt")
+	if err := os.WriteFile(other, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "upload-outside")
+	if err := os.WriteFile(outside, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantErr    bool
+		wantExists bool
+	}{
+		{"not an upload", other, true, true},
+		{"outside of the uploads directory", outside, true, true},
+		{"upload", upload, false, false},
+		{"already removed upload", upload, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Remove(tt.path); (err != nil) != tt.wantErr {
+				t.Fatalf("Remove() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(tt.path); (err == nil) != tt.wantExists {
+				t.Errorf("file %s exists = %v, want %v", tt.path, err == nil, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestFileStoreRemoveUnused(t *testing.T) {
+	store := newTestFileStore(t, 1024)
+
+	var uploads []string
+	for range 3 {
+		path, _, err := store.Save(strings.NewReader("data"), "data.txt")
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		uploads = append(uploads, path)
+	}
+	other := filepath.Join(store.dir, "other.txt")
+	if err := os.WriteFile(other, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.RemoveUnused(uploads[:1]); err != nil {
+		t.Fatalf("RemoveUnused() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantExists bool
+	}{
+		{"used upload", uploads[0], true},
+		{"unused upload", uploads[1], false},
+		{"other unused upload", uploads[2], false},
+		{"not an upload", other, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := os.Stat(tt.path); (err == nil) != tt.wantExists {
+				t.Errorf("file %s exists = %v, want %v", tt.path, err == nil, tt.wantExists)
+			}
+		})
+	}
+}